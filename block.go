@@ -0,0 +1,75 @@
+package tracerr
+
+import "sort"
+
+// mergedSources returns source rows of frames, the same as sourceRows does,
+// but consecutive frames in the same file with overlapping or close windows
+// share a single source block, which is displayed after the last of them.
+// joined[i] is true if source of frame i is displayed with the next frame.
+func (o *options) mergedSources(frames []displayFrame, budgets []sourceBudget) (sources [][]string, joined []bool) {
+	sources = make([][]string, len(frames))
+	joined = make([]bool, len(frames))
+	windows := make([][]sourceLine, len(frames))
+	for i, frame := range frames {
+		budget := budgets[frame.index]
+		if !budget.shown || frame.hidden > 0 {
+			continue
+		}
+		window, err := sourceWindow(frame.Frame, budget.before, budget.after)
+		if err != nil || len(window) == 0 {
+			sources[i] = sourceRows(nil, frame.Frame, budget.before, budget.after, o)
+			continue
+		}
+		windows[i] = window
+	}
+	for i := 0; i < len(frames); {
+		if windows[i] == nil {
+			i++
+			continue
+		}
+		block := []Frame{frames[i].Frame}
+		blockWindows := [][]sourceLine{windows[i]}
+		j := i + 1
+		for ; j < len(frames) && windows[j] != nil && frames[j].Path == frames[i].Path; j++ {
+			budget := budgets[frames[j].index]
+			if !windowsClose(windows[j-1], windows[j], budget.before+budget.after+1) {
+				break
+			}
+			block = append(block, frames[j].Frame)
+			blockWindows = append(blockWindows, windows[j])
+			joined[j-1] = true
+		}
+		sources[j-1] = windowRows(nil, block, mergeWindows(blockWindows), o)
+		i = j
+	}
+	return sources, joined
+}
+
+// windowsClose reports whether windows overlap or fewer than n lines are skipped between them.
+func windowsClose(a, b []sourceLine, n int) bool {
+	start := max(a[0].Number, b[0].Number)
+	end := min(a[len(a)-1].Number, b[len(b)-1].Number)
+	return start-end-1 < n
+}
+
+// mergeWindows returns lines of all windows in ascending order,
+// lines traced in any of windows are traced.
+func mergeWindows(windows [][]sourceLine) []sourceLine {
+	lines := map[int]sourceLine{}
+	for _, window := range windows {
+		for _, line := range window {
+			if merged, ok := lines[line.Number]; ok {
+				line.Traced = line.Traced || merged.Traced
+			}
+			lines[line.Number] = line
+		}
+	}
+	merged := make([]sourceLine, 0, len(lines))
+	for _, line := range lines {
+		merged = append(merged, line)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Number < merged[j].Number
+	})
+	return merged
+}
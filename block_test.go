@@ -0,0 +1,99 @@
+package tracerr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestWithMergedSource(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/main.go": {Data: []byte(strings.Join([]string{
+			"package main",
+			"",
+			"func foo() {",
+			"\tpanic(1)",
+			"}",
+			"",
+			"func main() {",
+			"\tfoo()",
+			"}",
+			"",
+			"",
+			"",
+			"",
+			"",
+			"",
+			"",
+			"",
+			"",
+			"",
+			"func init() {",
+			"\tmain()",
+			"}",
+		}, "\n"))},
+	})
+	err := tracerr.CustomError(errors.New("some error"), []tracerr.Frame{
+		{Func: "main.foo", Line: 4, Path: "/src/main.go"},
+		{Func: "main.main", Line: 8, Path: "/src/main.go"},
+		{Func: "main.init", Line: 21, Path: "/src/main.go"},
+		{Func: "runtime.main", Line: 250, Path: "/src/runtime/proc.go"},
+	})
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithSource(1, 1), tracerr.WithMergedSource(true))
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"/src/main.go:4 main.foo()",
+		"/src/main.go:8 main.main()",
+		"3\tfunc foo() {",
+		"4\t\tpanic(1)",
+		"5\t}",
+		"...",
+		"7\tfunc main() {",
+		"8\t\tfoo()",
+		"9\t}",
+		"",
+		"/src/main.go:21 main.init()",
+		"20\tfunc init() {",
+		"21\t\tmain()",
+		"22\t}",
+		"",
+		"/src/runtime/proc.go:250 runtime.main()",
+		"tracerr: file /src/runtime/proc.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithSource(2, 2), tracerr.WithMergedSource(true))
+	expectedPrefix := strings.Join([]string{
+		"some error",
+		"",
+		"/src/main.go:4 main.foo()",
+		"/src/main.go:8 main.main()",
+		" 2\t",
+		" 3\tfunc foo() {",
+		" 4\t\tpanic(1)",
+		" 5\t}",
+		" 6\t",
+		" 7\tfunc main() {",
+		" 8\t\tfoo()",
+		" 9\t}",
+		"10\t",
+		"",
+		"/src/main.go:21 main.init()",
+	}, "\n")
+	if !strings.HasPrefix(output, expectedPrefix) {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want prefix %#v", output, expectedPrefix)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithSource(1, 1))
+	if strings.Contains(output, "...") || !strings.Contains(output, "/src/main.go:4 main.foo()\n3\tfunc foo() {") {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want separate source fragments", output)
+	}
+}
@@ -0,0 +1,144 @@
+package tracerr
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultSourceCacheSize is a default number of source files to keep in cache.
+const DefaultSourceCacheSize = 256
+
+var cache = newSourceCache(DefaultSourceCacheSize)
+
+// SetSourceCacheSize sets a maximum number of source files to keep in cache,
+// least recently used files are evicted first.
+// If n <= 0 source files are not cached.
+func SetSourceCacheSize(n int) {
+	cache.resize(n)
+}
+
+// SetSourceCacheEnabled defines whether source files are cached, which is a default.
+// Disable it if source files change while program is running,
+// such as in development with hot reload.
+// Cache is cleared either way, so it starts fresh when enabled again.
+func SetSourceCacheEnabled(enabled bool) {
+	cache.setEnabled(enabled)
+}
+
+// ClearSourceCache removes all files from source cache.
+func ClearSourceCache() {
+	cache.clear()
+	clearHashMismatches()
+}
+
+// PreloadSource reads source files and puts them to cache,
+// so there is no file reading when error with these files is printed first time.
+// Files, which could not be read, are skipped.
+func PreloadSource(paths ...string) {
+	for _, path := range paths {
+		_, _ = readLines(path)
+	}
+}
+
+// PreloadFromError puts to cache all source files referenced by frames of err,
+// the same way as PreloadSource does.
+func PreloadFromError(err error) {
+	seen := map[string]bool{}
+	for _, frame := range StackTrace(err) {
+		if seen[frame.Path] {
+			continue
+		}
+		seen[frame.Path] = true
+		_, _ = readLines(frame.Path)
+	}
+}
+
+// sourceCache is a least recently used cache of source file lines.
+type sourceCache struct {
+	// mutex guards all fields, even lookups change the order of entries.
+	mutex    sync.Mutex
+	size     int
+	disabled bool
+	// order contains entries, most recently used first.
+	order *list.List
+	items map[string]*list.Element
+}
+
+type sourceCacheEntry struct {
+	path  string
+	lines []string
+}
+
+func newSourceCache(size int) *sourceCache {
+	return &sourceCache{
+		size:  size,
+		order: list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+func (c *sourceCache) get(path string) ([]string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.disabled {
+		return nil, false
+	}
+	item, ok := c.items[path]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(item)
+	return item.Value.(*sourceCacheEntry).lines, true
+}
+
+func (c *sourceCache) set(path string, lines []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.disabled {
+		return
+	}
+	if item, ok := c.items[path]; ok {
+		item.Value.(*sourceCacheEntry).lines = lines
+		c.order.MoveToFront(item)
+		return
+	}
+	if c.size <= 0 {
+		return
+	}
+	c.items[path] = c.order.PushFront(&sourceCacheEntry{
+		path:  path,
+		lines: lines,
+	})
+	c.evict()
+}
+
+func (c *sourceCache) resize(size int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.size = size
+	c.evict()
+}
+
+func (c *sourceCache) setEnabled(enabled bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.disabled = !enabled
+	c.order.Init()
+	c.items = map[string]*list.Element{}
+}
+
+func (c *sourceCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.order.Init()
+	c.items = map[string]*list.Element{}
+}
+
+// evict removes least recently used entries over the size limit.
+func (c *sourceCache) evict() {
+	for c.order.Len() > c.size && c.order.Len() > 0 {
+		item := c.order.Back()
+		c.order.Remove(item)
+		delete(c.items, item.Value.(*sourceCacheEntry).path)
+	}
+}
@@ -0,0 +1,154 @@
+package tracerr_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestSourceCache(t *testing.T) {
+	defer tracerr.SetSourceCacheSize(tracerr.DefaultSourceCacheSize)
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.go")
+	pathB := filepath.Join(dir, "b.go")
+	errA := sourceFileError(pathA)
+	errB := sourceFileError(pathB)
+
+	writeSourceFile(t, pathA, "old a")
+	writeSourceFile(t, pathB, "old b")
+	tracerr.SetSourceCacheSize(1)
+	assertSourceLine(t, "read a", errA, "1\told a")
+
+	// Cached content is returned.
+	writeSourceFile(t, pathA, "new a")
+	assertSourceLine(t, "cached a", errA, "1\told a")
+
+	// File a is evicted by file b.
+	assertSourceLine(t, "read b", errB, "1\told b")
+	assertSourceLine(t, "evicted a", errA, "1\tnew a")
+
+	tracerr.SetSourceCacheSize(2)
+	writeSourceFile(t, pathB, "new b")
+	assertSourceLine(t, "read b again", errB, "1\tnew b")
+	writeSourceFile(t, pathA, "newest a")
+	writeSourceFile(t, pathB, "newest b")
+	assertSourceLine(t, "cached a of 2", errA, "1\tnew a")
+	assertSourceLine(t, "cached b of 2", errB, "1\tnew b")
+
+	tracerr.ClearSourceCache()
+	assertSourceLine(t, "cleared a", errA, "1\tnewest a")
+	assertSourceLine(t, "cleared b", errB, "1\tnewest b")
+
+	tracerr.SetSourceCacheSize(0)
+	writeSourceFile(t, pathA, "uncached a")
+	assertSourceLine(t, "uncached a", errA, "1\tuncached a")
+}
+
+func sourceFileError(path string) error {
+	return tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.Foo",
+				Line: 1,
+				Path: path,
+			},
+		},
+	)
+}
+
+func writeSourceFile(t *testing.T, path, content string) {
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertSourceLine(t *testing.T, name string, err error, expected string) {
+	output := tracerr.SprintSource(err, 0, 0)
+	expectedRows := []string{
+		"some error",
+		"",
+		tracerr.StackTrace(err)[0].String(),
+		expected,
+	}
+	assertRows(t, 0, output, expectedRows, 0)
+	if t.Failed() {
+		t.Fatalf("%s: unexpected output %#v", name, output)
+	}
+}
+
+func TestSetSourceCacheEnabled(t *testing.T) {
+	defer tracerr.SetSourceCacheEnabled(true)
+	path := filepath.Join(t.TempDir(), "a.go")
+	err := sourceFileError(path)
+
+	writeSourceFile(t, path, "old a")
+	assertSourceLine(t, "read a", err, "1\told a")
+	writeSourceFile(t, path, "new a")
+	assertSourceLine(t, "cached a", err, "1\told a")
+
+	tracerr.SetSourceCacheEnabled(false)
+	assertSourceLine(t, "disabled a", err, "1\tnew a")
+	writeSourceFile(t, path, "newer a")
+	assertSourceLine(t, "still disabled a", err, "1\tnewer a")
+
+	// Cache starts fresh.
+	tracerr.SetSourceCacheEnabled(true)
+	assertSourceLine(t, "enabled a", err, "1\tnewer a")
+	writeSourceFile(t, path, "newest a")
+	assertSourceLine(t, "cached again a", err, "1\tnewer a")
+}
+
+// countingFS counts reads of each file.
+type countingFS struct {
+	fstest.MapFS
+	reads map[string]int
+}
+
+func (fsys countingFS) ReadFile(name string) ([]byte, error) {
+	fsys.reads[name]++
+	return fsys.MapFS.ReadFile(name)
+}
+
+func TestPreloadSource(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	fsys := countingFS{
+		MapFS: fstest.MapFS{
+			"src/a.go": {Data: []byte("package a")},
+			"src/b.go": {Data: []byte("package b")},
+		},
+		reads: map[string]int{},
+	}
+	tracerr.SetSourceFS(fsys)
+
+	tracerr.PreloadSource("/src/a.go", "/src/missing.go")
+	if fsys.reads["src/a.go"] != 1 {
+		t.Fatalf("reads of a.go = %#v; want %#v", fsys.reads["src/a.go"], 1)
+	}
+	assertSourceLine(t, "preloaded a", sourceFileError("/src/a.go"), "1\tpackage a")
+	if fsys.reads["src/a.go"] != 1 {
+		t.Errorf("reads of a.go = %#v; want %#v", fsys.reads["src/a.go"], 1)
+	}
+
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{Func: "main.Foo", Line: 1, Path: "/src/b.go"},
+			{Func: "main.Bar", Line: 1, Path: "/src/b.go"},
+			{Func: "main.Baz", Line: 1, Path: "/src/a.go"},
+		},
+	)
+	tracerr.PreloadFromError(err)
+	tracerr.PreloadFromError(nil)
+	tracerr.SprintSource(err)
+	if fsys.reads["src/b.go"] != 1 {
+		t.Errorf("reads of b.go = %#v; want %#v", fsys.reads["src/b.go"], 1)
+	}
+	if fsys.reads["src/a.go"] != 1 {
+		t.Errorf("reads of a.go = %#v; want %#v", fsys.reads["src/a.go"], 1)
+	}
+}
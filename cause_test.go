@@ -0,0 +1,66 @@
+package tracerr_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+var errRoot = errors.New("connection refused")
+
+func dial() error {
+	return tracerr.Wrap(errRoot)
+}
+
+func connect() error {
+	return tracerr.Wrapf(dial(), "connect to %s", "db")
+}
+
+func query() error {
+	return fmt.Errorf("query users: %w", connect())
+}
+
+func load() error {
+	return tracerr.Wrapf(query(), "load")
+}
+
+func TestWrapChain(t *testing.T) {
+	err := load()
+	message := "load: query users: connect to db: connection refused"
+	if err.Error() != message {
+		t.Errorf("err.Error() = %#v; want %#v", err.Error(), message)
+	}
+	frames := tracerr.StackTrace(err)
+	if len(frames) == 0 {
+		t.Fatalf("tracerr.StackTrace(err) is empty")
+	}
+	if fn := frames[0].Func; fn != "github.com/ztrue/tracerr_test.dial" {
+		t.Errorf("frames[0].Func = %#v; want %#v", fn, "github.com/ztrue/tracerr_test.dial")
+	}
+	if fn := frames[1].Func; fn != "github.com/ztrue/tracerr_test.connect" {
+		t.Errorf("frames[1].Func = %#v; want %#v", fn, "github.com/ztrue/tracerr_test.connect")
+	}
+	output := tracerr.Sprint(err)
+	if !strings.HasPrefix(output, message+"\n") || strings.Count(output, "tracerr_test.dial()") != 1 {
+		t.Errorf("tracerr.Sprint(err) = %#v; want message with single stack trace", output)
+	}
+	if cause := tracerr.Cause(err); cause != errRoot {
+		t.Errorf("tracerr.Cause(err) = %#v; want %#v", cause, errRoot)
+	}
+}
+
+func TestCause(t *testing.T) {
+	if cause := tracerr.Cause(nil); cause != nil {
+		t.Errorf("tracerr.Cause(nil) = %#v; want nil", cause)
+	}
+	if cause := tracerr.Cause(errRoot); cause != errRoot {
+		t.Errorf("tracerr.Cause(errRoot) = %#v; want %#v", cause, errRoot)
+	}
+	err := tracerr.New("some error")
+	if cause := tracerr.Cause(err); cause.Error() != "some error" {
+		t.Errorf("tracerr.Cause(err) = %#v; want %#v", cause.Error(), "some error")
+	}
+}
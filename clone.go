@@ -0,0 +1,47 @@
+package tracerr
+
+// Clone returns a copy of an error with the same message, stack trace and metadata.
+// Changes to frames of the copy don't affect the original error
+// and vice versa, so the copy could be sanitized before reporting.
+func (e *errorData) Clone() Error {
+	return e.WithFrames(e.StackTrace())
+}
+
+// WithFrames returns a copy of an error, the same way as Clone does,
+// with stack trace replaced by frames, such as with sensitive frames removed.
+// Frames are copied, so later changes to them don't affect the copy.
+func (e *errorData) WithFrames(frames []Frame) Error {
+	clone := e.clone()
+	clone.frames = nil
+	if frames != nil {
+		clone.frames = make([]Frame, len(frames))
+		copy(clone.frames, frames)
+	}
+	return clone
+}
+
+// Clone returns a copy of e with the same message, stack trace and metadata.
+// Changes to frames of the copy don't affect the original error
+// and vice versa, so the copy could be sanitized before reporting.
+// It returns nil if e is nil.
+func Clone(e Error) Error {
+	if e == nil {
+		return nil
+	}
+	return WithFrames(e, e.StackTrace())
+}
+
+// WithFrames returns a copy of e, the same way as Clone does,
+// with stack trace replaced by frames, such as with sensitive frames removed.
+// Frames are copied, so later changes to them don't affect the copy.
+// Errors implemented outside of this package are wrapped by a copy.
+// It returns nil if e is nil.
+func WithFrames(e Error, frames []Frame) Error {
+	if e == nil {
+		return nil
+	}
+	if c, ok := e.(interface{ WithFrames([]Frame) Error }); ok {
+		return c.WithFrames(frames)
+	}
+	return (&errorData{err: e}).WithFrames(frames)
+}
@@ -0,0 +1,62 @@
+package tracerr_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestClone(t *testing.T) {
+	original := tracerr.WithValue(tracerr.Wrapf(errors.New("some error"), "context"), "request_id", "42")
+	frames := original.StackTrace()
+
+	clone := tracerr.Clone(original)
+	if clone == original {
+		t.Fatalf("clone is the same error as original")
+	}
+	if clone.Error() != original.Error() {
+		t.Errorf("clone.Error() = %#v; want %#v", clone.Error(), original.Error())
+	}
+	if clone.Unwrap() != original.Unwrap() {
+		t.Errorf("clone.Unwrap() = %#v; want %#v", clone.Unwrap(), original.Unwrap())
+	}
+	if !reflect.DeepEqual(tracerr.Values(clone), tracerr.Values(original)) {
+		t.Errorf("tracerr.Values(clone) = %#v; want %#v", tracerr.Values(clone), tracerr.Values(original))
+	}
+	if !reflect.DeepEqual(clone.StackTrace(), frames) {
+		t.Errorf("clone.StackTrace() = %#v; want %#v", clone.StackTrace(), frames)
+	}
+
+	clone.StackTrace()[0].Path = "redacted"
+	if !reflect.DeepEqual(original.StackTrace(), frames) || frames[0].Path == "redacted" {
+		t.Errorf("original.StackTrace() = %#v; want unchanged", original.StackTrace())
+	}
+}
+
+func TestWithFrames(t *testing.T) {
+	original := tracerr.WithValue(errors.New("some error"), "user", "john")
+	frames := append([]tracerr.Frame(nil), original.StackTrace()...)
+
+	replacement := []tracerr.Frame{
+		{Func: "main.main", Line: 1, Path: "main.go"},
+	}
+	redacted := tracerr.WithFrames(original, replacement)
+	replacement[0].Path = "changed.go"
+	expected := []tracerr.Frame{
+		{Func: "main.main", Line: 1, Path: "main.go"},
+	}
+	if !reflect.DeepEqual(redacted.StackTrace(), expected) {
+		t.Errorf("redacted.StackTrace() = %#v; want %#v", redacted.StackTrace(), expected)
+	}
+	if redacted.Error() != "some error" {
+		t.Errorf("redacted.Error() = %#v; want %#v", redacted.Error(), "some error")
+	}
+	if tracerr.Values(redacted)["user"] != "john" {
+		t.Errorf("tracerr.Values(redacted) = %#v; want user", tracerr.Values(redacted))
+	}
+	if !reflect.DeepEqual(original.StackTrace(), frames) {
+		t.Errorf("original.StackTrace() = %#v; want %#v", original.StackTrace(), frames)
+	}
+}
@@ -0,0 +1,204 @@
+package tracerr
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ColorDepth defines which colors a terminal supports.
+type ColorDepth int
+
+const (
+	// ColorDepthAuto detects supported colors
+	// from COLORTERM and TERM environment variables.
+	ColorDepthAuto ColorDepth = iota
+	// ColorDepthBasic supports only 16 basic ANSI colors.
+	ColorDepthBasic
+	// ColorDepth256 supports 256 colors.
+	ColorDepth256
+	// ColorDepthTrueColor supports 24-bit colors.
+	ColorDepthTrueColor
+)
+
+var colorDepth = ColorDepthAuto
+
+// depthChecked is true once environment variables were checked for color depth.
+var depthChecked bool
+
+var detectedDepth ColorDepth
+
+// SetColorDepth sets which colors a terminal supports, ColorDepthAuto is used by default.
+// Theme colors, which are not supported, such as created by Color256 or RGB,
+// are replaced with the closest supported ones.
+func SetColorDepth(depth ColorDepth) {
+	colorMutex.Lock()
+	defer colorMutex.Unlock()
+	colorDepth = depth
+}
+
+// Color256 returns an escape sequence of a foreground color from 256-color palette,
+// which can be used in Theme.
+func Color256(n uint8) string {
+	return fmt.Sprintf("\x1b[38;5;%dm", n)
+}
+
+// RGB returns an escape sequence of a 24-bit foreground color,
+// which can be used in Theme.
+func RGB(r, g, b uint8) string {
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b)
+}
+
+// getColorDepth returns color depth set by SetColorDepth or detected one.
+func getColorDepth() ColorDepth {
+	colorMutex.RLock()
+	depth, checked, detected := colorDepth, depthChecked, detectedDepth
+	colorMutex.RUnlock()
+	if depth != ColorDepthAuto {
+		return depth
+	}
+	if checked {
+		return detected
+	}
+
+	colorMutex.Lock()
+	defer colorMutex.Unlock()
+	if !depthChecked {
+		detectedDepth = detectColorDepth(os.Getenv("COLORTERM"), os.Getenv("TERM"))
+		depthChecked = true
+	}
+	return detectedDepth
+}
+
+func detectColorDepth(colorterm, term string) ColorDepth {
+	if colorterm == "truecolor" || colorterm == "24bit" {
+		return ColorDepthTrueColor
+	}
+	if strings.Contains(term, "256color") {
+		return ColorDepth256
+	}
+	return ColorDepthBasic
+}
+
+// adaptColor replaces 256 and 24-bit colors in escape sequence code
+// with the closest colors supported with depth.
+func adaptColor(code string, depth ColorDepth) string {
+	if depth == ColorDepthTrueColor || !strings.HasPrefix(code, "\x1b[") || !strings.HasSuffix(code, "m") {
+		return code
+	}
+	params := strings.Split(code[2:len(code)-1], ";")
+	adapted := make([]string, 0, len(params))
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		if (p != "38" && p != "48") || i+1 >= len(params) {
+			adapted = append(adapted, p)
+			continue
+		}
+		background := p == "48"
+		switch {
+		case params[i+1] == "5" && i+2 < len(params):
+			n, err := strconv.Atoi(params[i+2])
+			if err != nil || depth == ColorDepth256 {
+				adapted = append(adapted, params[i:i+3]...)
+			} else {
+				adapted = append(adapted, basicColor(n, background))
+			}
+			i += 2
+		case params[i+1] == "2" && i+4 < len(params):
+			rgb := make([]int, 3)
+			var err error
+			for j := range rgb {
+				if rgb[j], err = strconv.Atoi(params[i+2+j]); err != nil {
+					break
+				}
+			}
+			switch {
+			case err != nil:
+				adapted = append(adapted, params[i:i+5]...)
+			case depth == ColorDepth256:
+				adapted = append(adapted, p, "5", strconv.Itoa(rgbTo256(rgb[0], rgb[1], rgb[2])))
+			default:
+				adapted = append(adapted, basicColor(rgbTo256(rgb[0], rgb[1], rgb[2]), background))
+			}
+			i += 4
+		default:
+			adapted = append(adapted, p)
+		}
+	}
+	return "\x1b[" + strings.Join(adapted, ";") + "m"
+}
+
+// rgbTo256 returns the closest color from 256-color palette.
+func rgbTo256(r, g, b int) int {
+	if r == g && g == b {
+		// Grayscale ramp from 232 to 255 has no pure black and white.
+		if r < 8 {
+			return 16
+		}
+		if r > 248 {
+			return 231
+		}
+		level := (r - 3) / 10
+		if level > 23 {
+			level = 23
+		}
+		return 232 + level
+	}
+	return 16 + 36*cubeLevel(r) + 6*cubeLevel(g) + cubeLevel(b)
+}
+
+// cubeValues are channel values of 6x6x6 color cube levels.
+var cubeValues = [6]int{0, 95, 135, 175, 215, 255}
+
+// cubeLevel returns the closest level of 6x6x6 color cube for a channel value.
+func cubeLevel(v int) int {
+	if v < 48 {
+		return 0
+	}
+	if v < 115 {
+		return 1
+	}
+	return (v - 35) / 40
+}
+
+// basicColor returns SGR parameter of the closest basic color
+// for a color from 256-color palette.
+func basicColor(n int, background bool) string {
+	base := 30
+	if background {
+		base = 40
+	}
+	var r, g, b int
+	switch {
+	case n < 8:
+		return strconv.Itoa(base + n)
+	case n < 16:
+		return strconv.Itoa(base + 60 + n - 8)
+	case n < 232:
+		n -= 16
+		r, g, b = cubeValues[n/36], cubeValues[n/6%6], cubeValues[n%6]
+	default:
+		r = 8 + (n-232)*10
+		g, b = r, r
+	}
+	code := 0
+	if r > 127 {
+		code |= 1
+	}
+	if g > 127 {
+		code |= 2
+	}
+	if b > 127 {
+		code |= 4
+	}
+	bright := r > 191 || g > 191 || b > 191
+	if code == 0 && r > 63 {
+		// Dark gray.
+		bright = true
+	}
+	if bright {
+		return strconv.Itoa(base + 60 + code)
+	}
+	return strconv.Itoa(base + code)
+}
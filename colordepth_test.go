@@ -0,0 +1,80 @@
+package tracerr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestColorConstructors(t *testing.T) {
+	if c := tracerr.Color256(208); c != "\x1b[38;5;208m" {
+		t.Errorf("tracerr.Color256(208) = %#v; want %#v", c, "\x1b[38;5;208m")
+	}
+	if c := tracerr.RGB(255, 135, 0); c != "\x1b[38;2;255;135;0m" {
+		t.Errorf("tracerr.RGB(255, 135, 0) = %#v; want %#v", c, "\x1b[38;2;255;135;0m")
+	}
+}
+
+type ColorDepthTestCase struct {
+	Depth    tracerr.ColorDepth
+	Color    string
+	Expected string
+}
+
+func TestSetColorDepth(t *testing.T) {
+	defer tracerr.SetTheme(tracerr.DefaultTheme)
+	defer tracerr.SetColorDepth(tracerr.ColorDepthAuto)
+	cases := []ColorDepthTestCase{
+		{tracerr.ColorDepthTrueColor, tracerr.RGB(255, 135, 0), "\x1b[38;2;255;135;0m"},
+		{tracerr.ColorDepthTrueColor, tracerr.Color256(208), "\x1b[38;5;208m"},
+		{tracerr.ColorDepth256, tracerr.RGB(255, 135, 0), "\x1b[38;5;208m"},
+		{tracerr.ColorDepth256, tracerr.RGB(128, 128, 128), "\x1b[38;5;244m"},
+		{tracerr.ColorDepth256, tracerr.Color256(208), "\x1b[38;5;208m"},
+		{tracerr.ColorDepthBasic, tracerr.Color256(208), "\x1b[93m"},
+		{tracerr.ColorDepthBasic, tracerr.Color256(1), "\x1b[31m"},
+		{tracerr.ColorDepthBasic, tracerr.Color256(12), "\x1b[94m"},
+		{tracerr.ColorDepthBasic, tracerr.RGB(0, 0, 0), "\x1b[30m"},
+		{tracerr.ColorDepthBasic, tracerr.RGB(128, 0, 0), "\x1b[31m"},
+		{tracerr.ColorDepthBasic, "\x1b[1;38;2;0;0;175m", "\x1b[1;34m"},
+		{tracerr.ColorDepthBasic, "\x1b[31m", "\x1b[31m"},
+	}
+	for i, c := range cases {
+		tracerr.SetColorDepth(c.Depth)
+		tracerr.SetTheme(tracerr.Theme{Message: c.Color})
+		output := tracerr.SprintSourceColor(newColorTestError(), 0)
+		expected := c.Expected + "some error\x1b[0m"
+		if !strings.HasPrefix(output, expected) {
+			t.Errorf("cases[%#v]: output = %#v; want prefix %#v", i, output, expected)
+		}
+	}
+}
+
+func TestColorDepthDetection(t *testing.T) {
+	defer tracerr.SetTheme(tracerr.DefaultTheme)
+	defer tracerr.SetColorMode(tracerr.ColorAlways)
+	tracerr.SetTheme(tracerr.Theme{Message: tracerr.RGB(255, 135, 0)})
+	cases := []struct {
+		ColorTerm string
+		Term      string
+		Expected  string
+	}{
+		{"truecolor", "xterm", "\x1b[38;2;255;135;0m"},
+		{"24bit", "", "\x1b[38;2;255;135;0m"},
+		{"", "xterm-256color", "\x1b[38;5;208m"},
+		{"", "xterm", "\x1b[93m"},
+		{"", "", "\x1b[93m"},
+	}
+	for i, c := range cases {
+		t.Setenv("COLORTERM", c.ColorTerm)
+		t.Setenv("TERM", c.Term)
+		tracerr.ResetColor()
+		tracerr.SetColorMode(tracerr.ColorAlways)
+		output := tracerr.SprintSourceColor(newColorTestError(), 0)
+		expected := c.Expected + "some error\x1b[0m"
+		if !strings.HasPrefix(output, expected) {
+			t.Errorf("cases[%#v]: output = %#v; want prefix %#v", i, output, expected)
+		}
+	}
+	tracerr.ResetColor()
+}
@@ -1,27 +1,140 @@
 package tracerr
 
 import (
-	"fmt"
+	"io"
+	"os"
+	"sync"
 )
 
 // Colorize outputs using [ANSI Escape Codes](https://en.wikipedia.org/wiki/ANSI_escape_code)
 
-func color(code int, in string) string {
-	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", code, in)
+// ColorMode defines when colorized output contains escape codes.
+type ColorMode int
+
+const (
+	// ColorAuto emits escape codes unless NO_COLOR environment variable is set.
+	// If destination of output is known (such as in Print and Fprint functions),
+	// escape codes are emitted only if it is a terminal.
+	ColorAuto ColorMode = iota
+	// ColorAlways always emits escape codes.
+	ColorAlways
+	// ColorNever never emits escape codes.
+	ColorNever
+)
+
+var colorMutex sync.RWMutex
+
+var colorMode = ColorAuto
+
+// noColorChecked is true once NO_COLOR environment variable was checked.
+var noColorChecked bool
+
+var noColor bool
+
+// SetColorMode sets when colorized output contains escape codes,
+// ColorAuto is used by default.
+func SetColorMode(mode ColorMode) {
+	colorMutex.Lock()
+	defer colorMutex.Unlock()
+	colorMode = mode
+}
+
+// SetColorEnabled forces colorized output on or off,
+// regardless of NO_COLOR environment variable and output destination.
+// It's the same as SetColorMode with ColorAlways or ColorNever.
+func SetColorEnabled(enabled bool) {
+	if enabled {
+		SetColorMode(ColorAlways)
+	} else {
+		SetColorMode(ColorNever)
+	}
+}
+
+// isColorEnabled reports whether escape codes should be emitted
+// when destination of output is unknown.
+func isColorEnabled() bool {
+	colorMutex.RLock()
+	mode, checked, disabled := colorMode, noColorChecked, noColor
+	colorMutex.RUnlock()
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if checked {
+		return !disabled
+	}
+
+	colorMutex.Lock()
+	defer colorMutex.Unlock()
+	if !noColorChecked {
+		// See https://no-color.org.
+		_, noColor = os.LookupEnv("NO_COLOR")
+		noColorChecked = true
+	}
+	return !noColor
+}
+
+// isColorEnabledFor reports whether escape codes should be emitted to w.
+func isColorEnabledFor(w io.Writer) bool {
+	if !isColorEnabled() {
+		return false
+	}
+	colorMutex.RLock()
+	mode := colorMode
+	colorMutex.RUnlock()
+	return mode == ColorAlways || isTerminal(w)
+}
+
+// isTerminal reports whether w is a character device, such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func color(code string, in string) string {
+	if code == "" || !isColorEnabled() {
+		return in
+	}
+	return adaptColor(code, getColorDepth()) + in + "\x1b[0m"
+}
+
+func messageColor(in string) string {
+	return color(getTheme().Message, in)
+}
+
+func headerColor(in string) string {
+	return color(getTheme().Header, in)
+}
+
+func lineNumberColor(in string) string {
+	return color(getTheme().LineNumber, in)
+}
+
+func tracedLineColor(in string) string {
+	return color(getTheme().TracedLine, in)
 }
 
-func bold(in string) string {
-	return color(1, in)
+func warningColor(in string) string {
+	return color(getTheme().Warning, in)
 }
 
-func black(in string) string {
-	return color(30, in)
+func keywordColor(in string) string {
+	return color(getTheme().Keyword, in)
 }
 
-func red(in string) string {
-	return color(31, in)
+func stringColor(in string) string {
+	return color(getTheme().String, in)
 }
 
-func yellow(in string) string {
-	return color(33, in)
+func commentColor(in string) string {
+	return color(getTheme().Comment, in)
 }
@@ -0,0 +1,152 @@
+package tracerr_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestMain(m *testing.M) {
+	// Expected output in tests is colorized, no matter of environment.
+	tracerr.SetColorMode(tracerr.ColorAlways)
+	os.Exit(m.Run())
+}
+
+func TestNoColor(t *testing.T) {
+	defer tracerr.SetColorMode(tracerr.ColorAlways)
+	err := newColorTestError()
+	plain := tracerr.SprintSource(err)
+
+	t.Setenv("NO_COLOR", "1")
+	tracerr.ResetColor()
+	output := tracerr.SprintSourceColor(err)
+	if output != plain {
+		t.Errorf(
+			"NO_COLOR: tracerr.SprintSourceColor(err) = %#v; want %#v",
+			output, plain,
+		)
+	}
+
+	tracerr.SetColorEnabled(true)
+	output = tracerr.SprintSourceColor(err)
+	if !strings.Contains(output, "\x1b[") {
+		t.Errorf(
+			"SetColorEnabled(true): tracerr.SprintSourceColor(err) = %#v; want escape codes",
+			output,
+		)
+	}
+
+	os.Unsetenv("NO_COLOR")
+	tracerr.ResetColor()
+	output = tracerr.SprintSourceColor(err)
+	if !strings.Contains(output, "\x1b[") {
+		t.Errorf(
+			"no NO_COLOR: tracerr.SprintSourceColor(err) = %#v; want escape codes",
+			output,
+		)
+	}
+
+	tracerr.SetColorEnabled(false)
+	output = tracerr.SprintSourceColor(err)
+	if output != plain {
+		t.Errorf(
+			"SetColorEnabled(false): tracerr.SprintSourceColor(err) = %#v; want %#v",
+			output, plain,
+		)
+	}
+}
+
+func TestColorMode(t *testing.T) {
+	defer tracerr.SetColorMode(tracerr.ColorAlways)
+	t.Setenv("NO_COLOR", "")
+	os.Unsetenv("NO_COLOR")
+	err := newColorTestError()
+	plain := tracerr.SprintSource(err) + "\n"
+	colorized := tracerr.SprintSourceColor(err) + "\n"
+
+	file, fileErr := os.CreateTemp("", "tracerr")
+	if fileErr != nil {
+		t.Fatal(fileErr)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	cases := []struct {
+		Mode             tracerr.ColorMode
+		ExpectedBuffer   string
+		ExpectedFile     string
+		ExpectedSprinted string
+	}{
+		{
+			Mode:             tracerr.ColorAuto,
+			ExpectedBuffer:   plain,
+			ExpectedFile:     plain,
+			ExpectedSprinted: colorized,
+		},
+		{
+			Mode:             tracerr.ColorAlways,
+			ExpectedBuffer:   colorized,
+			ExpectedFile:     colorized,
+			ExpectedSprinted: colorized,
+		},
+		{
+			Mode:             tracerr.ColorNever,
+			ExpectedBuffer:   plain,
+			ExpectedFile:     plain,
+			ExpectedSprinted: plain,
+		},
+	}
+
+	for i, c := range cases {
+		tracerr.ResetColor()
+		tracerr.SetColorMode(c.Mode)
+
+		var buf bytes.Buffer
+		tracerr.FprintSourceColor(&buf, err)
+		if buf.String() != c.ExpectedBuffer {
+			t.Errorf(
+				"case #%d: buffer output = %#v; want %#v",
+				i, buf.String(), c.ExpectedBuffer,
+			)
+		}
+
+		file.Truncate(0)
+		file.Seek(0, 0)
+		tracerr.FprintSourceColor(file, err)
+		b, readErr := os.ReadFile(file.Name())
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+		if string(b) != c.ExpectedFile {
+			t.Errorf(
+				"case #%d: file output = %#v; want %#v",
+				i, string(b), c.ExpectedFile,
+			)
+		}
+
+		sprinted := tracerr.SprintSourceColor(err) + "\n"
+		if sprinted != c.ExpectedSprinted {
+			t.Errorf(
+				"case #%d: tracerr.SprintSourceColor(err) = %#v; want %#v",
+				i, sprinted, c.ExpectedSprinted,
+			)
+		}
+	}
+}
+
+func newColorTestError() tracerr.Error {
+	return tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.Foo",
+				Line: 42,
+				Path: "/tmp/not_exists.go",
+			},
+		},
+	)
+}
@@ -0,0 +1,99 @@
+package tracerr_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func columnError(path string, line, col int) error {
+	return tracerr.CustomError(
+		errors.New("column error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.Parse",
+				Line: line,
+				Path: path,
+				Col:  col,
+			},
+		},
+	)
+}
+
+func TestSprintSourceColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	writeSourceFile(t, path, "1\n2\n3\n4\n5\n6\n7\n8\n9\nx = (1 + )\n\tfoo(bar, )\n12")
+
+	err := columnError(path, 10, 5)
+	output := tracerr.SprintSource(err, 1, 1)
+	expected := strings.Join([]string{
+		"column error",
+		"",
+		path + ":10 main.Parse()",
+		" 9\t9",
+		"10\tx = (1 + )",
+		"  \t    ^",
+		"11\t\tfoo(bar, )",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 1, 1) = %#v; want %#v", output, expected)
+	}
+
+	err = columnError(path, 11, 5)
+	output = tracerr.SprintSourceColor(err, 0, 0)
+	expected = strings.Join([]string{
+		"column error",
+		"",
+		bold(path + ":11 main.Parse()"),
+		red("11\t\tfoo(bar, )"),
+		red("  \t\t   ^"),
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSourceColor(err, 0, 0) = %#v; want %#v", output, expected)
+	}
+
+	// Caret is aligned with tabs replaced by spaces.
+	defer tracerr.SetTabWidth(0)
+	tracerr.SetTabWidth(2)
+	output = tracerr.SprintSource(err, 0, 0)
+	if !strings.HasSuffix(output, "11    foo(bar, )\n         ^") {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want caret under column", output)
+	}
+}
+
+func TestSprintSourceNoColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	writeSourceFile(t, path, "one\ntwo\nthree")
+
+	output := tracerr.SprintSource(columnError(path, 2, 0), 1, 1)
+	if strings.Contains(output, "^") {
+		t.Errorf("tracerr.SprintSource(err, 1, 1) = %#v; want no caret", output)
+	}
+}
+
+func TestSprintSourceColumnTruncated(t *testing.T) {
+	defer tracerr.SetMaxSourceLineWidth(0)
+	path := filepath.Join(t.TempDir(), "input.txt")
+	writeSourceFile(t, path, "abcdefghijklmnopqrstuvwxyz\n日本語日本語")
+	tracerr.SetMaxSourceLineWidth(10)
+
+	// Caret of cut off column is under the ellipsis.
+	output := tracerr.SprintSource(columnError(path, 1, 20), 0, 0)
+	if !strings.HasSuffix(output, "\n1\tabcdefghi…\n \t         ^") {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want caret under ellipsis", output)
+	}
+
+	output = tracerr.SprintSource(columnError(path, 1, 5), 0, 0)
+	if !strings.HasSuffix(output, "\n1\tabcdefghi…\n \t    ^") {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want caret under column", output)
+	}
+
+	// Caret is aligned by columns of wide characters.
+	output = tracerr.SprintSource(columnError(path, 2, 3), 0, 0)
+	if !strings.HasSuffix(output, "\n2\t日本語日…\n \t    ^") {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want caret under column", output)
+	}
+}
@@ -0,0 +1,68 @@
+package tracerr
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SprintCompact returns error message with stack trace in a single line,
+// such as for line-based log formats:
+//
+//	some error: main.foo(main.go:42) → main.main(main.go:10)
+//
+// Newlines in error message are replaced with spaces.
+// Displayed frames are the same as in Sprint,
+// unless changed by options, such as WithFrameSeparator.
+//
+// Errors created by Join and Group are displayed as a header followed by
+// each joined error or group in square brackets with its own frames:
+//
+//	2 errors occurred: [first: main.foo(main.go:42)] [second: main.bar(main.go:50)]
+func SprintCompact(err error, opts ...Option) string {
+	if err == nil {
+		return ""
+	}
+	return sprintCompact(err, newOptions(opts))
+}
+
+func sprintCompact(err error, o *options) string {
+	switch e := err.(type) {
+	case *joinError:
+		entries := make([]string, 0, len(e.errs))
+		for _, err := range e.errs {
+			entries = append(entries, sprintCompact(err, o))
+		}
+		return compactEntries(fmt.Sprintf("%d errors occurred", len(e.errs)), entries)
+	case *groupError:
+		entries := make([]string, 0, len(e.groups))
+		for _, group := range e.groups {
+			entries = append(entries, fmt.Sprintf("(×%d) %s", len(group), sprintCompact(group[0], o)))
+		}
+		return compactEntries(fmt.Sprintf("%d errors occurred in %d groups", len(e.errs), len(e.groups)), entries)
+	}
+	message := strings.ReplaceAll(err.Error(), "\n", " ")
+	e, ok := err.(Error)
+	if !ok {
+		return message
+	}
+	frames := o.ordered(o.selected(e.StackTrace()))
+	if len(frames) == 0 {
+		return message
+	}
+	entries := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		entries = append(entries, compactFrame(frame.Frame))
+	}
+	return message + ": " + strings.Join(entries, o.frameSeparator)
+}
+
+// compactEntries returns header followed by entries in square brackets.
+func compactEntries(header string, entries []string) string {
+	return header + ": [" + strings.Join(entries, "] [") + "]"
+}
+
+// compactFrame formats frame as func(file:line) with file name only.
+func compactFrame(frame Frame) string {
+	return fmt.Sprintf("%s(%s:%d)", frame.Func, filepath.Base(frame.Path), frame.Line)
+}
@@ -0,0 +1,153 @@
+package tracerr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+type SprintCompactTestCase struct {
+	Error    error
+	Expected string
+}
+
+func TestSprintCompact(t *testing.T) {
+	frames := []tracerr.Frame{
+		{Func: "main.a", Line: 10, Path: "/src/main.go"},
+		{Func: "main.b", Line: 20, Path: "/src/main.go"},
+		{Func: "main.main", Line: 30, Path: "/src/cmd/app.go"},
+	}
+	cases := []SprintCompactTestCase{
+		{
+			Error:    nil,
+			Expected: "",
+		},
+		{
+			Error:    errors.New("plain error"),
+			Expected: "plain error",
+		},
+		{
+			Error:    tracerr.CustomError(errors.New("boom"), frames),
+			Expected: "boom: main.a(main.go:10) → main.b(main.go:20) → main.main(app.go:30)",
+		},
+		{
+			Error:    tracerr.CustomError(errors.New("multi\nline"), frames[:1]),
+			Expected: "multi line: main.a(main.go:10)",
+		},
+		{
+			Error:    tracerr.CustomError(errors.New("no frames"), nil),
+			Expected: "no frames",
+		},
+	}
+	for i, c := range cases {
+		output := tracerr.SprintCompact(c.Error)
+		if output != c.Expected {
+			t.Errorf("cases[%#v]: tracerr.SprintCompact(err) = %#v; want %#v", i, output, c.Expected)
+		}
+	}
+}
+
+func TestSprintCompactOptions(t *testing.T) {
+	err := addFrameA("compact error")
+	frames := len(tracerr.StackTrace(err))
+	tracerr.SetDefaultMaxFrames(2)
+	tracerr.SetDefaultIgnoreFrames(1, 0)
+	defer func() {
+		tracerr.SetDefaultMaxFrames(0)
+		tracerr.SetDefaultIgnoreFrames(0, 0)
+	}()
+	output := tracerr.SprintCompact(err)
+	expected := "compact error: github.com/ztrue/tracerr_test.addFrameB(error_helper_test.go:13) → " +
+		"github.com/ztrue/tracerr_test.addFrameA(error_helper_test.go:9)"
+	if output != expected {
+		t.Errorf("tracerr.SprintCompact(err) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetDefaultMaxFrames(0)
+	output = tracerr.SprintCompact(err)
+	if strings.Contains(output, "\n") {
+		t.Errorf("tracerr.SprintCompact(err) = %#v; want no newlines", output)
+	}
+	if entries := strings.Count(output, " → ") + 1; entries != frames-1 {
+		t.Errorf("entries = %#v; want %#v", entries, frames-1)
+	}
+}
+
+func TestSprintCompactFrameSeparator(t *testing.T) {
+	frames := []tracerr.Frame{
+		{Func: "main.a", Line: 10, Path: "/src/main.go"},
+		{Func: "main.b", Line: 20, Path: "/src/main.go"},
+		{Func: "main.main", Line: 30, Path: "/src/cmd/app.go"},
+	}
+	err := tracerr.CustomError(errors.New("boom"), frames)
+	output := tracerr.SprintCompact(err, tracerr.WithFrameSeparator(" | "))
+	expected := "boom: main.a(main.go:10) | main.b(main.go:20) | main.main(app.go:30)"
+	if output != expected {
+		t.Errorf("tracerr.SprintCompact(err, ...) = %#v; want %#v", output, expected)
+	}
+	if count := strings.Count(output, " | "); count != len(frames)-1 {
+		t.Errorf("strings.Count(output, %#v) = %#v; want %#v", " | ", count, len(frames)-1)
+	}
+	if strings.Contains(output, "→") {
+		t.Errorf("tracerr.SprintCompact(err, ...) = %#v; want no default separator", output)
+	}
+	output = tracerr.SprintCompact(tracerr.CustomError(errors.New("boom"), frames[:1]), tracerr.WithFrameSeparator(" | "))
+	if strings.Contains(output, " | ") {
+		t.Errorf("tracerr.SprintCompact(err, ...) = %#v; want no separator for single frame", output)
+	}
+}
+
+func TestFrameSeparatorSingleLineOnly(t *testing.T) {
+	err := tracerr.CustomError(errors.New("boom"), []tracerr.Frame{
+		{Func: "main.inlined", Line: 10, Path: "/src/main.go"},
+		{Func: "main.outer", Line: 10, Path: "/src/main.go"},
+		{Func: "main.main", Line: 30, Path: "/src/cmd/app.go"},
+	})
+	separator := tracerr.WithFrameSeparator(" | ")
+	outputs := []string{
+		tracerr.SprintWithOptions(err, separator),
+		tracerr.SprintWithOptions(err, separator, tracerr.WithSource(0, 0)),
+		tracerr.SprintWithOptions(err, separator, tracerr.WithMergeInlined(true)),
+		tracerr.SprintWithOptions(err, separator, tracerr.WithMergeInlined(true), tracerr.WithAlignedHeaders(true)),
+		tracerr.SprintWithOptions(tracerr.Join(err, errors.New("other")), separator, tracerr.WithTree(true)),
+	}
+	for _, output := range outputs {
+		if strings.Contains(output, " | ") {
+			t.Errorf("output = %#v; want no separator in multi-line output", output)
+		}
+	}
+	if output := outputs[2]; !strings.Contains(output, "\n/src/main.go:10 main.outer() → main.inlined()\n") {
+		t.Errorf("output = %#v; want merged header with default arrow", output)
+	}
+}
+
+func TestSprintCompactJoin(t *testing.T) {
+	first := tracerr.CustomError(errors.New("first error"), []tracerr.Frame{
+		{Func: "main.foo", Line: 42, Path: "/src/main.go"},
+		{Func: "main.main", Line: 10, Path: "/src/main.go"},
+	})
+	second := tracerr.CustomError(errors.New("second error"), []tracerr.Frame{
+		{Func: "main.bar", Line: 50, Path: "/src/main.go"},
+	})
+	third := tracerr.CustomError(errors.New("third error"), []tracerr.Frame{
+		{Func: "main.foo", Line: 42, Path: "/src/main.go"},
+	})
+
+	output := tracerr.SprintCompact(tracerr.Join(first, tracerr.Join(second, third)))
+	expected := "2 errors occurred: " +
+		"[first error: main.foo(main.go:42) → main.main(main.go:10)] " +
+		"[2 errors occurred: [second error: main.bar(main.go:50)] [third error: main.foo(main.go:42)]]"
+	if output != expected {
+		t.Errorf("tracerr.SprintCompact(err) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintCompact(tracerr.Group(first, second, third), tracerr.WithMaxFrames(1))
+	expected = "3 errors occurred in 2 groups: " +
+		"[(×2) first error: main.foo(main.go:42)] " +
+		"[(×1) second error: main.bar(main.go:50)]"
+	if output != expected {
+		t.Errorf("tracerr.SprintCompact(err, ...) = %#v; want %#v", output, expected)
+	}
+}
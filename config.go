@@ -0,0 +1,100 @@
+package tracerr
+
+import (
+	"sync"
+)
+
+// configMutex guards settings defined by package variables,
+// such as DefaultLinesBefore or DefaultMaxFrames.
+var configMutex sync.RWMutex
+
+// Defaults are settings defined by package variables, such as DefaultMaxFrames.
+type Defaults struct {
+	LinesBefore       int
+	LinesAfter        int
+	MaxFrames         int
+	IgnoreFirstFrames int
+	IgnoreLastFrames  int
+	ReverseFrames     bool
+	SyntaxHighlight   bool
+	Cap               int
+}
+
+// CurrentDefaults returns settings defined by package variables.
+// Unlike reading variables, it's safe for concurrent use with setters.
+func CurrentDefaults() Defaults {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	return Defaults{
+		LinesBefore:       DefaultLinesBefore,
+		LinesAfter:        DefaultLinesAfter,
+		MaxFrames:         DefaultMaxFrames,
+		IgnoreFirstFrames: DefaultIgnoreFirstFrames,
+		IgnoreLastFrames:  DefaultIgnoreLastFrames,
+		ReverseFrames:     DefaultReverseFrames,
+		SyntaxHighlight:   DefaultSyntaxHighlight,
+		Cap:               DefaultCap,
+	}
+}
+
+// SetDefaultLines sets DefaultLinesBefore and DefaultLinesAfter.
+// Unlike assigning variables, it's safe for concurrent use with printing.
+func SetDefaultLines(before, after int) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	DefaultLinesBefore = before
+	DefaultLinesAfter = after
+}
+
+// SetDefaultMaxFrames sets DefaultMaxFrames.
+// Unlike assigning a variable, it's safe for concurrent use with printing.
+func SetDefaultMaxFrames(n int) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	DefaultMaxFrames = n
+}
+
+// SetDefaultIgnoreFrames sets DefaultIgnoreFirstFrames and DefaultIgnoreLastFrames.
+// Unlike assigning variables, it's safe for concurrent use with printing.
+func SetDefaultIgnoreFrames(first, last int) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	DefaultIgnoreFirstFrames = first
+	DefaultIgnoreLastFrames = last
+}
+
+// SetDefaultReverseFrames sets DefaultReverseFrames.
+// Unlike assigning a variable, it's safe for concurrent use with printing.
+func SetDefaultReverseFrames(reverse bool) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	DefaultReverseFrames = reverse
+}
+
+// SetDefaultSyntaxHighlight sets DefaultSyntaxHighlight.
+// Unlike assigning a variable, it's safe for concurrent use with printing.
+func SetDefaultSyntaxHighlight(enabled bool) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	DefaultSyntaxHighlight = enabled
+}
+
+// SetDefaultCap sets DefaultCap.
+// Unlike assigning a variable, it's safe for concurrent use with creating errors.
+func SetDefaultCap(n int) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	DefaultCap = n
+}
+
+func defaultCap() int {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	return DefaultCap
+}
+
+func defaultLines() (before, after int) {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	return DefaultLinesBefore, DefaultLinesAfter
+}
@@ -0,0 +1,95 @@
+package tracerr_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestConcurrentConfig(t *testing.T) {
+	defer func() {
+		tracerr.SetDefaultLines(3, 2)
+		tracerr.SetDefaultMaxFrames(0)
+		tracerr.SetDefaultIgnoreFrames(0, 0)
+		tracerr.SetDefaultReverseFrames(false)
+		tracerr.SetDefaultSyntaxHighlight(false)
+		tracerr.SetDefaultCap(20)
+	}()
+	err := addFrameA("config error")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			tracerr.SetDefaultLines(i, i)
+			tracerr.SetDefaultMaxFrames(i)
+			tracerr.SetDefaultIgnoreFrames(i%2, i%3)
+			tracerr.SetDefaultReverseFrames(i%2 == 0)
+			tracerr.SetDefaultSyntaxHighlight(i%2 == 1)
+			tracerr.SetDefaultCap(i)
+		}(i)
+		go func() {
+			defer wg.Done()
+			tracerr.CurrentDefaults()
+			tracerr.New("config error")
+			tracerr.Sprint(err)
+			tracerr.SprintSource(err)
+			tracerr.SprintSourceColor(err)
+		}()
+	}
+	wg.Wait()
+
+	tracerr.SetDefaultLines(1, 0)
+	tracerr.SetDefaultMaxFrames(1)
+	tracerr.SetDefaultIgnoreFrames(0, 0)
+	tracerr.SetDefaultReverseFrames(false)
+	output := tracerr.SprintSource(err)
+	expectedRows := []string{
+		"config error",
+		"",
+		"/tracerr/error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"16\tfunc addFrameC(message string) error {",
+		"17\t\treturn tracerr.New(message)",
+	}
+	assertRows(t, 0, output, expectedRows, 0)
+}
+
+func TestCurrentDefaults(t *testing.T) {
+	defer func() {
+		tracerr.SetDefaultLines(3, 2)
+		tracerr.SetDefaultMaxFrames(0)
+		tracerr.SetDefaultIgnoreFrames(0, 0)
+		tracerr.SetDefaultReverseFrames(false)
+		tracerr.SetDefaultSyntaxHighlight(false)
+		tracerr.SetDefaultCap(20)
+	}()
+	expected := tracerr.Defaults{
+		LinesBefore: 3,
+		LinesAfter:  2,
+		Cap:         20,
+	}
+	if defaults := tracerr.CurrentDefaults(); defaults != expected {
+		t.Errorf("tracerr.CurrentDefaults() = %#v; want %#v", defaults, expected)
+	}
+
+	tracerr.SetDefaultLines(1, 4)
+	tracerr.SetDefaultMaxFrames(5)
+	tracerr.SetDefaultIgnoreFrames(2, 3)
+	tracerr.SetDefaultReverseFrames(true)
+	tracerr.SetDefaultSyntaxHighlight(true)
+	tracerr.SetDefaultCap(8)
+	expected = tracerr.Defaults{
+		LinesBefore:       1,
+		LinesAfter:        4,
+		MaxFrames:         5,
+		IgnoreFirstFrames: 2,
+		IgnoreLastFrames:  3,
+		ReverseFrames:     true,
+		SyntaxHighlight:   true,
+		Cap:               8,
+	}
+	if defaults := tracerr.CurrentDefaults(); defaults != expected {
+		t.Errorf("tracerr.CurrentDefaults() = %#v; want %#v", defaults, expected)
+	}
+}
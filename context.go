@@ -0,0 +1,23 @@
+package tracerr
+
+import (
+	"context"
+)
+
+type contextKey struct{}
+
+// WithError returns a copy of ctx, which carries err.
+// It returns ctx as is if err is nil.
+func WithError(ctx context.Context, err error) context.Context {
+	if err == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, err)
+}
+
+// FromContext returns error stored in ctx by WithError,
+// or nil if there is no error.
+func FromContext(ctx context.Context) error {
+	err, _ := ctx.Value(contextKey{}).(error)
+	return err
+}
@@ -0,0 +1,43 @@
+package tracerr_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if err := tracerr.FromContext(ctx); err != nil {
+		t.Errorf("tracerr.FromContext(ctx) = %#v; want nil", err)
+	}
+
+	if c := tracerr.WithError(ctx, nil); c != ctx {
+		t.Errorf("tracerr.WithError(ctx, nil) = %#v; want %#v", c, ctx)
+	}
+
+	first := tracerr.New("first error")
+	ctx = tracerr.WithError(ctx, first)
+	if err := tracerr.FromContext(ctx); err != first {
+		t.Errorf("tracerr.FromContext(ctx) = %#v; want %#v", err, first)
+	}
+
+	// Storing nil keeps previous error.
+	ctx = tracerr.WithError(ctx, nil)
+	if err := tracerr.FromContext(ctx); err != first {
+		t.Errorf("tracerr.FromContext(ctx) = %#v; want %#v", err, first)
+	}
+
+	second := tracerr.New("second error")
+	child := tracerr.WithError(ctx, second)
+	if err := tracerr.FromContext(child); err != second {
+		t.Errorf("tracerr.FromContext(child) = %#v; want %#v", err, second)
+	}
+	if err := tracerr.FromContext(ctx); err != first {
+		t.Errorf("tracerr.FromContext(ctx) = %#v; want %#v", err, first)
+	}
+	if len(tracerr.StackTrace(tracerr.FromContext(child))) == 0 {
+		t.Errorf("tracerr.StackTrace(tracerr.FromContext(child)) is empty")
+	}
+}
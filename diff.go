@@ -0,0 +1,51 @@
+package tracerr
+
+// CommonFrames returns frames shared by stack traces of a and b,
+// which are compared from the outermost frame,
+// such as main.main, towards the innermost one.
+// Frames are returned in the same order as in StackTrace, innermost first.
+// It returns nil if there are no common frames
+// or either of errors has no stack trace.
+func CommonFrames(a, b error) []Frame {
+	framesA := StackTrace(a)
+	framesB := StackTrace(b)
+	n := commonFrames(framesA, framesB)
+	if n == 0 {
+		return nil
+	}
+	common := make([]Frame, n)
+	copy(common, framesA[len(framesA)-n:])
+	return common
+}
+
+// DivergePoint returns the first frame of a, which differs from b,
+// when stack traces are compared the same way as in CommonFrames.
+// It's the frame of a called from the innermost common frame.
+// It returns false if either of errors has no stack trace,
+// or if all frames of a are common.
+func DivergePoint(a, b error) (Frame, bool) {
+	framesA := StackTrace(a)
+	framesB := StackTrace(b)
+	if len(framesA) == 0 || len(framesB) == 0 {
+		return Frame{}, false
+	}
+	n := commonFrames(framesA, framesB)
+	if n == len(framesA) {
+		return Frame{}, false
+	}
+	return framesA[len(framesA)-n-1], true
+}
+
+// commonFrames returns a number of common outermost frames of a and b.
+func commonFrames(a, b []Frame) int {
+	n := 0
+	for n < len(a) && n < len(b) && sameFrame(a[len(a)-1-n], b[len(b)-1-n]) {
+		n++
+	}
+	return n
+}
+
+// sameFrame reports whether a and b point to the same line of the same function.
+func sameFrame(a, b Frame) bool {
+	return a.Func == b.Func && a.Path == b.Path && a.Line == b.Line
+}
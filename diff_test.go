@@ -0,0 +1,92 @@
+package tracerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+var (
+	diffMain    = tracerr.Frame{Func: "main.main", Line: 10, Path: "/src/main.go"}
+	diffServe   = tracerr.Frame{Func: "main.serve", Line: 20, Path: "/src/main.go"}
+	diffHandleA = tracerr.Frame{Func: "main.handleA", Line: 30, Path: "/src/handle.go"}
+	diffHandleB = tracerr.Frame{Func: "main.handleB", Line: 40, Path: "/src/handle.go"}
+	diffQuery   = tracerr.Frame{Func: "main.query", Line: 50, Path: "/src/db.go"}
+)
+
+func diffError(frames ...tracerr.Frame) error {
+	return tracerr.CustomError(errors.New("diff error"), frames)
+}
+
+type DiffTestCase struct {
+	A              error
+	B              error
+	ExpectedCommon []tracerr.Frame
+	ExpectedFrame  tracerr.Frame
+	ExpectedOK     bool
+}
+
+func TestCommonFrames(t *testing.T) {
+	cases := []DiffTestCase{
+		{
+			A:              diffError(diffQuery, diffHandleA, diffServe, diffMain),
+			B:              diffError(diffQuery, diffHandleB, diffServe, diffMain),
+			ExpectedCommon: []tracerr.Frame{diffServe, diffMain},
+			ExpectedFrame:  diffHandleA,
+			ExpectedOK:     true,
+		},
+		{
+			A:              diffError(diffHandleA, diffServe, diffMain),
+			B:              diffError(diffQuery, diffHandleA, diffServe, diffMain),
+			ExpectedCommon: []tracerr.Frame{diffHandleA, diffServe, diffMain},
+			ExpectedOK:     false,
+		},
+		{
+			A:              diffError(diffQuery, diffHandleA, diffServe, diffMain),
+			B:              diffError(diffHandleA, diffServe, diffMain),
+			ExpectedCommon: []tracerr.Frame{diffHandleA, diffServe, diffMain},
+			ExpectedFrame:  diffQuery,
+			ExpectedOK:     true,
+		},
+		{
+			A:              diffError(diffHandleA, diffMain),
+			B:              diffError(diffHandleA, diffServe),
+			ExpectedCommon: nil,
+			ExpectedFrame:  diffMain,
+			ExpectedOK:     true,
+		},
+		{
+			A:              diffError(diffHandleA, diffMain),
+			B:              errors.New("plain error"),
+			ExpectedCommon: nil,
+			ExpectedOK:     false,
+		},
+		{
+			A:              nil,
+			B:              diffError(diffHandleA, diffMain),
+			ExpectedCommon: nil,
+			ExpectedOK:     false,
+		},
+	}
+
+	for i, c := range cases {
+		common := tracerr.CommonFrames(c.A, c.B)
+		if len(common) != len(c.ExpectedCommon) || (common == nil) != (c.ExpectedCommon == nil) {
+			t.Errorf("cases[%#v]: tracerr.CommonFrames(a, b) = %#v; want %#v", i, common, c.ExpectedCommon)
+		} else {
+			for j, frame := range common {
+				if frame != c.ExpectedCommon[j] {
+					t.Errorf("cases[%#v]: common[%#v] = %#v; want %#v", i, j, frame, c.ExpectedCommon[j])
+				}
+			}
+		}
+		frame, ok := tracerr.DivergePoint(c.A, c.B)
+		if ok != c.ExpectedOK || frame != c.ExpectedFrame {
+			t.Errorf(
+				"cases[%#v]: tracerr.DivergePoint(a, b) = %#v, %#v; want %#v, %#v",
+				i, frame, ok, c.ExpectedFrame, c.ExpectedOK,
+			)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package tracerr
+
+import (
+	"embed"
+	"io/fs"
+	"strings"
+)
+
+// EmbedSource returns filesystem to read source fragments from files embedded into binary,
+// which is passed to SetSourceFS, so binary displays its own source
+// with no original files on disk:
+//
+//	//go:embed *.go
+//	var source embed.FS
+//
+//	tracerr.SetSourceFS(tracerr.EmbedSource(source, "/home/john/project"))
+//
+// Frame paths under prefix, which is a directory of embedding package on build machine,
+// or its import path for binaries built with -trimpath, are read from efs by paths relative to prefix.
+// Other paths are not found.
+func EmbedSource(efs embed.FS, prefix string) fs.FS {
+	return embedFS{
+		efs:    efs,
+		prefix: strings.TrimRight(fsPath(prefix), "/"),
+	}
+}
+
+// embedFS translates frame paths to paths in embedded filesystem.
+type embedFS struct {
+	efs    embed.FS
+	prefix string
+}
+
+func (fsys embedFS) Open(name string) (fs.File, error) {
+	rel, ok := fsys.rel(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return fsys.efs.Open(rel)
+}
+
+func (fsys embedFS) ReadFile(name string) ([]byte, error) {
+	rel, ok := fsys.rel(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return fsys.efs.ReadFile(rel)
+}
+
+// rel returns name relative to prefix.
+func (fsys embedFS) rel(name string) (string, bool) {
+	if fsys.prefix == "" {
+		return name, true
+	}
+	rel, ok := strings.CutPrefix(name, fsys.prefix+"/")
+	return rel, ok && rel != ""
+}
@@ -0,0 +1,64 @@
+package tracerr_test
+
+import (
+	"embed"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+//go:embed error_helper_test.go
+var embeddedSource embed.FS
+
+func TestEmbedSource(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameC",
+				Line: 17,
+				Path: "/build/tracerr/error_helper_test.go",
+			},
+			{
+				Func: "main.main",
+				Line: 1,
+				Path: "/build/other/main.go",
+			},
+		},
+	)
+
+	tracerr.SetSourceFS(tracerr.EmbedSource(embeddedSource, "/build/tracerr/"))
+	output := tracerr.SprintSource(err, 0, 0)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"/build/tracerr/error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"17\t\treturn tracerr.New(message)",
+		"",
+		"/build/other/main.go:1 main.main()",
+		"tracerr: file /build/other/main.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want %#v", output, expected)
+	}
+
+	// Paths of binaries built with -trimpath start with import path.
+	tracerr.SetSourceFS(tracerr.EmbedSource(embeddedSource, "github.com/ztrue/tracerr"))
+	trimmed := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameC",
+				Line: 17,
+				Path: "github.com/ztrue/tracerr/error_helper_test.go",
+			},
+		},
+	)
+	output = tracerr.SprintSource(trimmed, 0, 0)
+	if !strings.HasSuffix(output, "\n17\t\treturn tracerr.New(message)") {
+		t.Errorf("tracerr.SprintSource(trimmed, 0, 0) = %#v; want embedded source", output)
+	}
+}
@@ -5,16 +5,53 @@
 package tracerr
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // DefaultCap is a default cap for frames array.
 // It can be changed to number of expected frames
 // for purpose of performance optimisation.
+//
+// Deprecated: Use SetDefaultCap, which is safe for concurrent use.
+// Assigning the variable while other goroutines create errors is a data race.
 var DefaultCap = 20
 
+var maxCaptureDepth atomic.Int64
+
+// SetMaxCaptureDepth sets a maximum number of frames captured on error creation,
+// 0 means no limit, which is a default.
+//
+// Unlike DefaultMaxFrames, which limits only output,
+// it limits stack trace itself, so outer frames are not available at all.
+// This reduces cost of every new error, especially in deep recursion.
+func SetMaxCaptureDepth(n int) {
+	maxCaptureDepth.Store(int64(n))
+}
+
+var lazyStackTrace atomic.Bool
+
+// SetLazyStackTrace defines whether frames are resolved lazily, it's disabled by default.
+//
+// In lazy mode only program counters are captured on error creation,
+// which is much cheaper, and frames are resolved on the first StackTrace call.
+// It reduces allocations of error creation, but doesn't remove them,
+// since program counters and error itself are still allocated.
+func SetLazyStackTrace(enabled bool) {
+	lazyStackTrace.Store(enabled)
+}
+
 // Error is an error with stack trace.
+//
+// Error() returns only error message with no stack trace.
+// Metadata of errors created by this package, such as goroutine ID or timestamp,
+// is available with package functions, such as GoroutineID or Timestamp,
+// so Error could still be implemented outside of this package.
 type Error interface {
 	Error() string
 	StackTrace() []Frame
@@ -24,8 +61,25 @@ type Error interface {
 type errorData struct {
 	// err contains original error.
 	err error
+	// message contains error message if it differs from original error message.
+	message string
 	// frames contains stack trace of an error.
 	frames []Frame
+	// pcs contains program counters of stack trace,
+	// which are resolved to frames lazily, if any.
+	pcs []uintptr
+	// once guards resolving of pcs to frames.
+	once sync.Once
+	// goroutineID contains ID of goroutine where error was created.
+	goroutineID int
+	// timestamp contains time when error was created.
+	timestamp time.Time
+	// values contains metadata attached by WithValue.
+	values map[string]string
+	// panicValue contains value recovered from panic, if error is created from it.
+	panicValue interface{}
+	// kind contains a category of an error, set by NewWithKind or WithKind.
+	kind Kind
 }
 
 // CustomError creates an error with provided frames.
@@ -36,19 +90,94 @@ func CustomError(err error, frames []Frame) Error {
 	}
 }
 
+// FromPCs creates new error with stack trace of program counters,
+// such as captured by runtime.Callers or another tracing library,
+// so they are not captured again.
+// Program counters are resolved to frames the same way as native stack traces,
+// when frames are requested first time.
+// GoroutineID is 0, since program counters could be captured by any goroutine.
+func FromPCs(message string, pcs []uintptr) Error {
+	return created(&errorData{
+		err:       errors.New(message),
+		pcs:       append([]uintptr(nil), pcs...),
+		timestamp: timestamp(),
+	})
+}
+
 // Errorf creates new error with stacktrace and formatted message.
-// Formatting works the same way as in fmt.Errorf.
+// Formatting works the same way as in fmt.Errorf,
+// including %w verb, so wrapped error is available with errors.Unwrap
+// on the result of Unwrap, as well as with errors.Is and errors.As.
 func Errorf(message string, args ...interface{}) Error {
-	return trace(fmt.Errorf(message, args...), 2)
+	return created(trace(fmt.Errorf(message, args...), 2))
 }
 
 // New creates new error with stacktrace.
 func New(message string) Error {
-	return trace(fmt.Errorf(message), 2)
+	return created(trace(fmt.Errorf(message), 2))
+}
+
+// NewWithSkip creates new error with stacktrace, skipping a number of frames,
+// so constructors built on top of New can hide their own frames.
+// With skip 0 it's the same as New, so stack trace starts at caller of NewWithSkip,
+// with skip 1 it starts at caller of that caller and so on.
+func NewWithSkip(message string, skip int) Error {
+	if skip < 0 {
+		skip = 0
+	}
+	return created(trace(fmt.Errorf(message), 2+skip))
 }
 
 // Wrap adds stacktrace to existing error.
+//
+// If err is already of type Error, it's returned as is.
+// If there is an Error in err chain, such as in fmt.Errorf("%w", err) result,
+// its stack trace is used instead of a new one,
+// so the stack trace always points to the origin of an error.
+// Errors which unwrap to several errors, such as Join results,
+// get a new stack trace, since there is no single origin for them.
 func Wrap(err error) Error {
+	return wrap(err, 0)
+}
+
+// WrapWithSkip adds stacktrace to existing error, skipping a number of frames,
+// the same way as NewWithSkip does.
+// With skip 0 it's the same as Wrap.
+func WrapWithSkip(err error, skip int) Error {
+	if skip < 0 {
+		skip = 0
+	}
+	return wrap(err, skip)
+}
+
+// WrapReturn adds stacktrace to error returned by a function with a named result,
+// the same way as Wrap does, if it's not nil:
+//
+//	func load() (err error) {
+//		defer tracerr.WrapReturn(&err)
+//		...
+//	}
+//
+// Stack trace starts at the function, which returned error,
+// so there is no need to wrap errors at every return statement.
+func WrapReturn(errp *error) {
+	if errp == nil || *errp == nil {
+		return
+	}
+	// Skip frames of runtime running deferred calls, if there are any,
+	// such as when defer is not inlined or function exits by panic.
+	skip := 1
+	for {
+		pc, _, _, ok := runtime.Caller(skip)
+		if !ok || !strings.HasPrefix(runtime.FuncForPC(pc).Name(), "runtime.") {
+			break
+		}
+		skip++
+	}
+	*errp = wrap(*errp, skip-1)
+}
+
+func wrap(err error, skip int) Error {
 	if err == nil {
 		return nil
 	}
@@ -56,7 +185,69 @@ func Wrap(err error) Error {
 	if ok {
 		return e
 	}
-	return trace(err, 2)
+	if e, ok := chainError(err); ok {
+		wrapped := dataOf(e).clone()
+		wrapped.err = err
+		wrapped.message = ""
+		return created(wrapped)
+	}
+	// Skip wrap and its exported caller.
+	return created(trace(err, 3+skip))
+}
+
+// chainError finds an Error in err chain, the same way as errors.As does,
+// but it stops at errors which unwrap to several errors, such as Join results,
+// so stack trace of one of them isn't taken for all of them.
+func chainError(err error) (Error, bool) {
+	for err != nil {
+		if e, ok := err.(Error); ok {
+			return e, true
+		}
+		if _, ok := err.(interface{ Unwrap() []error }); ok {
+			return nil, false
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil, false
+}
+
+// Wrapf adds stacktrace to existing error and prepends formatted message to it,
+// so error message looks like "message: original message".
+// Formatting works the same way as in fmt.Errorf.
+//
+// If err is already of type Error or there is an Error in err chain,
+// its stack trace is preserved, so wrapping several times accumulates messages
+// with a single stack trace pointing to the origin of an error.
+// Unwrap returns err.
+func Wrapf(err error, message string, args ...interface{}) Error {
+	if err == nil {
+		return nil
+	}
+	message = fmt.Sprintf(message, args...) + ": " + err.Error()
+	e, ok := chainError(err)
+	if !ok {
+		wrapped := trace(err, 2).(*errorData)
+		wrapped.message = message
+		return created(wrapped)
+	}
+	wrapped := dataOf(e).clone()
+	wrapped.err = err
+	wrapped.message = message
+	return created(wrapped)
+}
+
+// Cause returns the root cause of err,
+// which is the innermost error of err chain, unwrapped from all wrappers,
+// including Error and fmt.Errorf("%w", err) results.
+func Cause(err error) error {
+	for err != nil {
+		cause := errors.Unwrap(err)
+		if cause == nil {
+			return err
+		}
+		err = cause
+	}
+	return nil
 }
 
 // Unwrap returns the original error.
@@ -71,13 +262,109 @@ func Unwrap(err error) error {
 	return e.Unwrap()
 }
 
-// Error returns error message.
+// clone returns a copy of e with frames resolved,
+// so any field of the copy could be changed with no effect on e.
+// Frames and values are shared, since they are never changed in place.
+func (e *errorData) clone() *errorData {
+	return &errorData{
+		err:         e.err,
+		message:     e.message,
+		frames:      e.StackTrace(),
+		goroutineID: e.goroutineID,
+		timestamp:   e.timestamp,
+		values:      e.values,
+		panicValue:  e.panicValue,
+		kind:        e.kind,
+	}
+}
+
+// dataOf returns e as errorData, so it could be cloned.
+// Errors implemented outside of this package have only their stack trace copied.
+func dataOf(e Error) *errorData {
+	if d, ok := e.(*errorData); ok {
+		return d
+	}
+	return &errorData{err: e, frames: e.StackTrace()}
+}
+
+// derive returns a copy of the first Error in err chain with data changed by set,
+// such as metadata attached, and with the same message as err.
+// If there is no Error in err chain, stack trace is added and OnCreate hooks are called
+// the same way as in Wrap, skip is the same as in wrap.
+func derive(err error, skip int, set func(data *errorData)) Error {
+	e, ok := err.(Error)
+	if !ok {
+		e, ok = chainError(err)
+	}
+	if !ok {
+		// Skip derive and its exported caller.
+		data := trace(err, 3+skip).(*errorData)
+		set(data)
+		return created(data)
+	}
+	data := dataOf(e).clone()
+	if _, ok := err.(*errorData); !ok {
+		data.err = err
+		data.message = ""
+	}
+	set(data)
+	return data
+}
+
+// Error returns error message with no stack trace.
 func (e *errorData) Error() string {
+	if e.message != "" {
+		return e.message
+	}
 	return e.err.Error()
 }
 
+// Message returns error message with no stack trace, the same as Error.
+func (e *errorData) Message() string {
+	return e.Error()
+}
+
+// StackString returns stack trace frames, one per line, with no error message.
+// Displayed frames are the same as in Sprint.
+func (e *errorData) StackString() string {
+	return strings.Join(stackStrings(e.StackTrace()), "\n")
+}
+
+// Message returns error message with no stack trace, the same as err.Error(),
+// such as to log message and stack trace separately.
+// It returns an empty string if err is nil.
+func Message(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// StackString returns frames of err stack trace, one per line, with no error message,
+// the same way as StackTrace finds them.
+// Displayed frames are the same as in Sprint.
+func StackString(err error) string {
+	return strings.Join(stackStrings(StackTrace(err)), "\n")
+}
+
+// stackStrings returns displayed frames formatted the same way as in Sprint.
+func stackStrings(stackTrace []Frame) []string {
+	o := newOptions(nil)
+	frames := o.ordered(o.selected(stackTrace))
+	stack := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		stack = append(stack, frame.String())
+	}
+	return stack
+}
+
 // StackTrace returns stack trace of an error.
 func (e *errorData) StackTrace() []Frame {
+	if e.pcs != nil {
+		e.once.Do(func() {
+			e.frames = resolveFrames(e.pcs)
+		})
+	}
 	return e.frames
 }
 
@@ -86,6 +373,38 @@ func (e *errorData) Unwrap() error {
 	return e.err
 }
 
+// GoroutineID returns ID of goroutine where error was created.
+// It will be 0 if capturing is disabled, see SetCaptureGoroutineID.
+func (e *errorData) GoroutineID() int {
+	return e.goroutineID
+}
+
+// Timestamp returns time when error was created.
+// It will be zero if capturing is disabled, see SetCaptureTimestamp.
+func (e *errorData) Timestamp() time.Time {
+	return e.timestamp
+}
+
+// GoroutineID returns ID of goroutine where the first Error in err chain was created.
+// It will be 0 if there is no such Error or capturing is disabled, see SetCaptureGoroutineID.
+func GoroutineID(err error) int {
+	var e interface{ GoroutineID() int }
+	if !errors.As(err, &e) {
+		return 0
+	}
+	return e.GoroutineID()
+}
+
+// Timestamp returns time when the first Error in err chain was created.
+// It will be zero if there is no such Error or capturing is disabled, see SetCaptureTimestamp.
+func Timestamp(err error) time.Time {
+	var e interface{ Timestamp() time.Time }
+	if !errors.As(err, &e) {
+		return time.Time{}
+	}
+	return e.Timestamp()
+}
+
 // Frame is a single step in stack trace.
 type Frame struct {
 	// Func contains a function name.
@@ -94,41 +413,163 @@ type Frame struct {
 	Line int
 	// Path contains a file path.
 	Path string
+	// EndLine contains the last line number, if frame points to a range of lines,
+	// such as a parser error spanning several lines.
+	// Zero means a single line.
+	EndLine int
+	// Col contains a column number of traced line, starting from 1,
+	// such as a position of invalid token in a parser error.
+	// Zero means unknown column, as for frames captured from runtime.
+	Col int
 }
 
 // StackTrace returns stack trace of an error.
-// It will be empty if err is not of type Error.
+// If err is not of type Error, stack trace of the first Error
+// in err chain is returned, such as for fmt.Errorf("%w", err) result.
+// It will be empty if there is no Error in err chain.
 func StackTrace(err error) []Frame {
 	e, ok := err.(Error)
-	if !ok {
+	if !ok && !errors.As(err, &e) {
 		return nil
 	}
 	return e.StackTrace()
 }
 
+// FrameCount returns a number of captured frames of err stack trace,
+// the same way as StackTrace does, regardless of frames ignored or filtered in output,
+// such as to choose between compact and full output of deep stack traces.
+// It returns 0 if there is no Error in err chain.
+func FrameCount(err error) int {
+	return len(StackTrace(err))
+}
+
+// HasStack reports whether there is an Error with frames in err chain,
+// such as to add stack trace only to errors which don't have one.
+// Errors which unwrap to several errors, such as Join results, are checked as well.
+func HasStack(err error) bool {
+	for err != nil {
+		if e, ok := err.(Error); ok && len(e.StackTrace()) > 0 {
+			return true
+		}
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, err := range multi.Unwrap() {
+				if HasStack(err) {
+					return true
+				}
+			}
+			return false
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
+// Origin returns the innermost frame of err stack trace, where error was created,
+// skipping frames the same way as DefaultIgnoreFirstFrames does in output.
+// It returns false if there is no Error in err chain or no frames to return.
+func Origin(err error) (Frame, bool) {
+	configMutex.RLock()
+	first := DefaultIgnoreFirstFrames
+	configMutex.RUnlock()
+	if first < 0 {
+		first = 0
+	}
+	frames := StackTrace(err)
+	if first >= len(frames) {
+		return Frame{}, false
+	}
+	return frames[first], true
+}
+
 // String formats Frame to string.
+// Path is displayed according to SetTrimPath.
 func (f Frame) String() string {
-	return fmt.Sprintf("%s:%d %s()", f.Path, f.Line, f.Func)
+	return fmt.Sprintf("%s:%d %s()", displayPath(f.Path), f.Line, f.Func)
+}
+
+// FuncName returns function name with no package path,
+// such as Foo, (*T).Method or Foo.func1 for anonymous function.
+func (f Frame) FuncName() string {
+	return f.Func[len(f.packagePath()):]
+}
+
+// Package returns package path of a function, such as github.com/john/doe.
+func (f Frame) Package() string {
+	return strings.TrimSuffix(f.packagePath(), ".")
+}
+
+// packagePath returns package path of a function, including trailing dot.
+// Package name ends at the first dot after the last slash,
+// since dots in package name are escaped in function names.
+func (f Frame) packagePath() string {
+	start := strings.LastIndex(f.Func, "/") + 1
+	end := strings.Index(f.Func[start:], ".")
+	if end < 0 {
+		return ""
+	}
+	return f.Func[:start+end+1]
 }
 
 func trace(err error, skip int) Error {
-	frames := make([]Frame, 0, DefaultCap)
+	e := &errorData{
+		err:         err,
+		goroutineID: goroutineID(),
+		timestamp:   timestamp(),
+	}
+	if lazyStackTrace.Load() {
+		e.pcs = callers(skip)
+	} else {
+		e.frames = stack(skip)
+	}
+	return e
+}
+
+// callers returns program counters of current goroutine,
+// skip is the same as in runtime.Caller called by callers caller.
+func callers(skip int) []uintptr {
+	// Skip runtime.Callers and callers itself.
+	skip += 2
+	depth := int(maxCaptureDepth.Load())
+	// Buffer grows until it fits, so it must not be empty.
+	size := max(defaultCap(), 1)
+	if depth > 0 {
+		size = depth
+	}
 	for {
-		pc, path, line, ok := runtime.Caller(skip)
-		if !ok {
-			break
-		}
-		fn := runtime.FuncForPC(pc)
-		frame := Frame{
-			Func: fn.Name(),
-			Line: line,
-			Path: path,
+		pcs := make([]uintptr, size)
+		n := runtime.Callers(skip, pcs)
+		if n < size || depth > 0 {
+			return pcs[:n]
 		}
-		frames = append(frames, frame)
-		skip++
+		size *= 2
 	}
-	return &errorData{
-		err:    err,
-		frames: frames,
+}
+
+// resolveFrames converts program counters to frames.
+func resolveFrames(pcs []uintptr) []Frame {
+	frames := make([]Frame, 0, len(pcs))
+	iter := runtime.CallersFrames(pcs)
+	for {
+		f, more := iter.Next()
+		if f.PC != 0 {
+			frames = append(frames, Frame{
+				Func: f.Function,
+				Line: f.Line,
+				Path: f.File,
+			})
+		}
+		if !more {
+			break
+		}
 	}
+	return frames
+}
+
+// stack returns frames of current goroutine,
+// skip is the same as in runtime.Caller called by stack caller.
+// Program counters are captured with a single runtime.Callers call,
+// which allocates less than calling runtime.Caller for each frame.
+func stack(skip int) []Frame {
+	// Skip stack itself.
+	return resolveFrames(callers(skip + 1))
 }
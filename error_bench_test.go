@@ -1,6 +1,7 @@
 package tracerr_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -32,3 +33,54 @@ func addFrames(depth int, message string) error {
 	}
 	return addFrames(depth-1, message)
 }
+
+func BenchmarkMaxCaptureDepth(b *testing.B) {
+	defer tracerr.SetMaxCaptureDepth(0)
+	for _, depth := range []int{0, 5, 20} {
+		suffix := fmt.Sprintf("%d", depth)
+		b.Run(suffix, func(b *testing.B) {
+			b.ReportAllocs()
+			tracerr.SetMaxCaptureDepth(depth)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				addFrames(40, "test error")
+			}
+		})
+	}
+}
+
+func BenchmarkLazyStackTrace(b *testing.B) {
+	defer tracerr.SetLazyStackTrace(false)
+	for _, lazy := range []bool{false, true} {
+		suffix := "eager"
+		if lazy {
+			suffix = "lazy"
+		}
+		b.Run(suffix, func(b *testing.B) {
+			b.ReportAllocs()
+			tracerr.SetLazyStackTrace(lazy)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				addFrames(20, "test error")
+			}
+		})
+	}
+}
+
+func BenchmarkErrorf(b *testing.B) {
+	cause := errors.New("cause")
+	b.Run("fmt", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = fmt.Errorf("test error %d: %w", i, cause)
+		}
+	})
+	b.Run("tracerr", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = tracerr.Errorf("test error %d: %w", i, cause)
+		}
+	})
+}
@@ -308,3 +308,315 @@ func TestUnwrap(t *testing.T) {
 func wrapError(err error) error {
 	return tracerr.Wrap(err)
 }
+
+type codeError struct {
+	Code int
+}
+
+func (e *codeError) Error() string {
+	return fmt.Sprintf("code %d", e.Code)
+}
+
+func TestErrorsIsAs(t *testing.T) {
+	sentinel := errors.New("sentinel error")
+	cases := []error{
+		tracerr.Wrap(sentinel),
+		fmt.Errorf("context: %w", tracerr.Wrap(sentinel)),
+		tracerr.Wrap(fmt.Errorf("context: %w", tracerr.Wrap(sentinel))),
+		tracerr.Wrap(wrapError(sentinel)),
+	}
+
+	for i, err := range cases {
+		if !errors.Is(err, sentinel) {
+			t.Errorf(
+				"errors.Is(cases[%#v], sentinel) = false; want true",
+				i,
+			)
+		}
+	}
+
+	codeErr := &codeError{Code: 404}
+	cases = []error{
+		tracerr.Wrap(codeErr),
+		fmt.Errorf("context: %w", tracerr.Wrap(codeErr)),
+		tracerr.Wrap(fmt.Errorf("context: %w", tracerr.Wrap(codeErr))),
+	}
+
+	for i, err := range cases {
+		var target *codeError
+		if !errors.As(err, &target) {
+			t.Errorf(
+				"errors.As(cases[%#v], &target) = false; want true",
+				i,
+			)
+			continue
+		}
+		if target != codeErr {
+			t.Errorf(
+				"errors.As(cases[%#v], &target): target = %#v; want %#v",
+				i, target, codeErr,
+			)
+		}
+	}
+
+	if errors.Is(tracerr.New("sentinel error"), sentinel) {
+		t.Errorf("errors.Is(tracerr.New(...), sentinel) = true; want false")
+	}
+}
+
+func TestWrapf(t *testing.T) {
+	if err := tracerr.Wrapf(nil, "while loading %s", "config"); err != nil {
+		t.Errorf("tracerr.Wrapf(nil, ...) = %#v; want nil", err)
+	}
+
+	original := errors.New("original message")
+	err := tracerr.Wrapf(original, "while loading %s", "config")
+	expected := "while loading config: original message"
+	if err.Error() != expected {
+		t.Errorf("err.Error() = %#v; want %#v", err.Error(), expected)
+	}
+	if err.Unwrap() != original {
+		t.Errorf("err.Unwrap() = %#v; want %#v", err.Unwrap(), original)
+	}
+	if !errors.Is(err, original) {
+		t.Errorf("errors.Is(err, original) = false; want true")
+	}
+	frame := err.StackTrace()[0]
+	if frame.Func != "github.com/ztrue/tracerr_test.TestWrapf" {
+		t.Errorf("err.StackTrace()[0].Func = %#v; want %#v", frame.Func, "github.com/ztrue/tracerr_test.TestWrapf")
+	}
+
+	traced := addFrameA("original message")
+	err = tracerr.Wrapf(traced, "while loading %s", "config")
+	if err.Error() != expected {
+		t.Errorf("err.Error() = %#v; want %#v", err.Error(), expected)
+	}
+	if err.Unwrap() != traced {
+		t.Errorf("err.Unwrap() = %#v; want %#v", err.Unwrap(), traced)
+	}
+	frames := tracerr.StackTrace(traced)
+	if len(err.StackTrace()) != len(frames) {
+		t.Fatalf("len(err.StackTrace()) = %#v; want %#v", len(err.StackTrace()), len(frames))
+	}
+	for i, frame := range frames {
+		if err.StackTrace()[i] != frame {
+			t.Errorf("err.StackTrace()[%#v] = %#v; want %#v", i, err.StackTrace()[i], frame)
+		}
+	}
+}
+
+func TestWrapTraced(t *testing.T) {
+	errs := []error{
+		wrapTwice(func() error {
+			return addFrameA("original error")
+		}),
+		wrapTwice(func() error {
+			return fmt.Errorf("context: %w", addFrameA("original error"))
+		}),
+	}
+
+	for i, err := range errs {
+		frames := tracerr.StackTrace(err)
+		if len(frames) == 0 {
+			t.Fatalf("cases[%#v]: tracerr.StackTrace(err) is empty", i)
+		}
+		expected := "github.com/ztrue/tracerr_test.addFrameC"
+		if frames[0].Func != expected {
+			t.Errorf(
+				"cases[%#v]: tracerr.StackTrace(err)[0].Func = %#v; want %#v",
+				i, frames[0].Func, expected,
+			)
+		}
+		if frames[0].Line != 17 {
+			t.Errorf(
+				"cases[%#v]: tracerr.StackTrace(err)[0].Line = %#v; want %#v",
+				i, frames[0].Line, 17,
+			)
+		}
+	}
+
+	err := tracerr.Wrap(fmt.Errorf("context: %w", addFrameA("original error")))
+	if err.Error() != "context: original error" {
+		t.Errorf("err.Error() = %#v; want %#v", err.Error(), "context: original error")
+	}
+}
+
+func wrapTwice(fn func() error) error {
+	return wrapOnce(fn)
+}
+
+func wrapOnce(fn func() error) error {
+	return tracerr.Wrap(tracerr.Wrap(fn()))
+}
+
+func TestStackTraceChain(t *testing.T) {
+	cases := []error{
+		errors.New("regular error"),
+		fmt.Errorf("context: %w", errors.New("regular error")),
+	}
+	for i, err := range cases {
+		if frames := tracerr.StackTrace(err); frames != nil {
+			t.Errorf("tracerr.StackTrace(cases[%#v]) = %#v; want nil", i, frames)
+		}
+	}
+
+	traced := addFrameA("traced error")
+	expected := traced.(tracerr.Error).StackTrace()
+	cases = []error{
+		traced,
+		fmt.Errorf("context: %w", traced),
+		fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", traced)),
+	}
+	for i, err := range cases {
+		frames := tracerr.StackTrace(err)
+		if len(frames) != len(expected) {
+			t.Errorf(
+				"len(tracerr.StackTrace(cases[%#v])) = %#v; want %#v",
+				i, len(frames), len(expected),
+			)
+			continue
+		}
+		for j := range expected {
+			if frames[j] != expected[j] {
+				t.Errorf(
+					"tracerr.StackTrace(cases[%#v])[%#v] = %#v; want %#v",
+					i, j, frames[j], expected[j],
+				)
+			}
+		}
+	}
+}
+
+func TestSetMaxCaptureDepth(t *testing.T) {
+	defer tracerr.SetMaxCaptureDepth(0)
+	tracerr.SetMaxCaptureDepth(2)
+	frames := tracerr.StackTrace(addFrameA("some error"))
+	if len(frames) != 2 {
+		t.Fatalf("len(frames) = %#v; want %#v", len(frames), 2)
+	}
+	if frames[0].Func != "github.com/ztrue/tracerr_test.addFrameC" {
+		t.Errorf("frames[0].Func = %#v; want %#v", frames[0].Func, "github.com/ztrue/tracerr_test.addFrameC")
+	}
+	if frames[1].Func != "github.com/ztrue/tracerr_test.addFrameB" {
+		t.Errorf("frames[1].Func = %#v; want %#v", frames[1].Func, "github.com/ztrue/tracerr_test.addFrameB")
+	}
+
+	tracerr.SetMaxCaptureDepth(0)
+	if frames := tracerr.StackTrace(addFrameA("some error")); len(frames) < 6 {
+		t.Errorf("len(frames) = %#v; want >= %#v", len(frames), 6)
+	}
+}
+
+func TestSetLazyStackTrace(t *testing.T) {
+	defer tracerr.SetLazyStackTrace(false)
+	errs := make([]error, 0, 2)
+	for _, lazy := range []bool{false, true} {
+		tracerr.SetLazyStackTrace(lazy)
+		errs = append(errs, addFrameA("some error"))
+	}
+	eager := tracerr.StackTrace(errs[0])
+	lazy := tracerr.StackTrace(errs[1])
+	if len(lazy) != len(eager) {
+		t.Fatalf("len(lazy) = %#v; want %#v", len(lazy), len(eager))
+	}
+	for i := range eager {
+		if lazy[i] != eager[i] {
+			t.Errorf("lazy[%#v] = %#v; want %#v", i, lazy[i], eager[i])
+		}
+	}
+
+	// Frames are resolved once, concurrent calls are safe.
+	tracerr.SetLazyStackTrace(true)
+	err := addFrameA("some error").(tracerr.Error)
+	done := make(chan []tracerr.Frame)
+	for i := 0; i < 4; i++ {
+		go func() {
+			done <- err.StackTrace()
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		frames := <-done
+		if len(frames) != len(eager) || frames[0] != eager[0] {
+			t.Errorf("err.StackTrace() = %#v; want %#v", frames, eager)
+		}
+	}
+
+	tracerr.SetMaxCaptureDepth(2)
+	defer tracerr.SetMaxCaptureDepth(0)
+	if frames := tracerr.StackTrace(addFrameA("some error")); len(frames) != 2 {
+		t.Errorf("len(frames) = %#v; want %#v", len(frames), 2)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	defer tracerr.SetCaptureGoroutineID(false)
+	tracerr.SetCaptureGoroutineID(true)
+	cases := []tracerr.Error{
+		addFrameA("some error").(tracerr.Error),
+		tracerr.Wrap(errors.New("some error")),
+		tracerr.Errorf("some %s", "error"),
+	}
+
+	for i, err := range cases {
+		for _, message := range []string{tracerr.Message(err), err.Error()} {
+			if message != "some error" {
+				t.Errorf("cases[%#v].Message() = %#v; want %#v", i, message, "some error")
+			}
+			if strings.Contains(message, ".go") || strings.Contains(message, "goroutine") {
+				t.Errorf("cases[%#v].Message() = %#v; want no stack trace", i, message)
+			}
+		}
+
+		stack := tracerr.StackString(err)
+		if strings.Contains(stack, "some error") {
+			t.Errorf("cases[%#v].StackString() = %#v; want no message", i, stack)
+		}
+		// Output without message and goroutine rows.
+		expected := strings.SplitN(tracerr.Sprint(err), "\n", 3)[2]
+		if stack != expected {
+			t.Errorf("cases[%#v].StackString() = %#v; want %#v", i, stack, expected)
+		}
+	}
+}
+
+func TestCustomErrorPrintsAsCaptured(t *testing.T) {
+	err := tracerr.New("captured error")
+	customErr := tracerr.CustomError(tracerr.Unwrap(err), err.StackTrace())
+	output := tracerr.SprintSource(customErr)
+	expected := tracerr.SprintSource(err)
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+}
+
+func TestErrorfWrapVerb(t *testing.T) {
+	cause := errors.New("cause")
+	err := tracerr.Errorf("while loading %s: %w", "config", cause)
+	if err.Error() != "while loading config: cause" {
+		t.Errorf("err.Error() = %#v; want %#v", err.Error(), "while loading config: cause")
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is(err, cause) = false; want true")
+	}
+	if unwrapped := errors.Unwrap(errors.Unwrap(err)); unwrapped != cause {
+		t.Errorf("errors.Unwrap(errors.Unwrap(err)) = %#v; want %#v", unwrapped, cause)
+	}
+	if len(err.StackTrace()) == 0 {
+		t.Errorf("len(err.StackTrace()) = 0; want > 0")
+	}
+}
+
+func TestDefaultCapNotPositive(t *testing.T) {
+	defer tracerr.SetDefaultCap(20)
+	defer tracerr.SetLazyStackTrace(false)
+	for _, lazy := range []bool{false, true} {
+		tracerr.SetLazyStackTrace(lazy)
+		for _, capacity := range []int{0, -1} {
+			tracerr.SetDefaultCap(capacity)
+			err := tracerr.New("some error")
+			if len(err.StackTrace()) == 0 {
+				t.Errorf("lazy = %#v, DefaultCap = %#v: len(err.StackTrace()) = 0; want > 0", lazy, capacity)
+			}
+		}
+	}
+}
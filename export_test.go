@@ -0,0 +1,44 @@
+package tracerr
+
+import (
+	"os"
+	"time"
+)
+
+// ResetColor restores ColorAuto mode and ColorDepthAuto depth
+// and forgets cached environment checks.
+func ResetColor() {
+	colorMutex.Lock()
+	defer colorMutex.Unlock()
+	colorMode = ColorAuto
+	noColorChecked = false
+	colorDepth = ColorDepthAuto
+	depthChecked = false
+}
+
+// ResetCreateHooks removes all hooks registered by OnCreate.
+func ResetCreateHooks() {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	createHooks = nil
+}
+
+// SetTerminalQuery replaces lookup of terminal size by query,
+// nil restores it.
+func SetTerminalQuery(query func(f *os.File) (int, bool)) {
+	if query == nil {
+		query = terminalColumns
+	}
+	queryTerminal = query
+}
+
+// SetRateLimitClock replaces clock of rate limit intervals by now,
+// nil restores it.
+func SetRateLimitClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	rateLimit.mutex.Lock()
+	defer rateLimit.mutex.Unlock()
+	rateLimitClock = now
+}
@@ -0,0 +1,25 @@
+package tracerr
+
+// Fields returns err as fields for structured logging:
+//
+//	{"error": "some error", "trace": ["/src/main.go:42 main.foo()", ...], "caller": "main.foo(main.go:42)"}
+//
+// Trace contains frames formatted the same way as in Sprint,
+// caller is the innermost frame, where error was created.
+// It returns only "error" field if err has no stack trace,
+// and nil if err is nil.
+func Fields(err error) map[string]interface{} {
+	if err == nil {
+		return nil
+	}
+	fields := map[string]interface{}{
+		"error": err.Error(),
+	}
+	stackTrace := StackTrace(err)
+	if len(stackTrace) == 0 {
+		return fields
+	}
+	fields["trace"] = stackStrings(stackTrace)
+	fields["caller"] = compactFrame(stackTrace[0])
+	return fields
+}
@@ -0,0 +1,66 @@
+package tracerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestFields(t *testing.T) {
+	err := addFrameA("fields error")
+	fields := tracerr.Fields(err)
+	if fields["error"] != "fields error" {
+		t.Errorf("fields[\"error\"] = %#v; want %#v", fields["error"], "fields error")
+	}
+	caller := "github.com/ztrue/tracerr_test.addFrameC(error_helper_test.go:17)"
+	if fields["caller"] != caller {
+		t.Errorf("fields[\"caller\"] = %#v; want %#v", fields["caller"], caller)
+	}
+	trace, ok := fields["trace"].([]string)
+	frames := tracerr.StackTrace(err)
+	if !ok || len(trace) != len(frames) {
+		t.Fatalf("fields[\"trace\"] = %#v; want %#v frames", fields["trace"], len(frames))
+	}
+	for i, frame := range frames {
+		if trace[i] != frame.String() {
+			t.Errorf("trace[%#v] = %#v; want %#v", i, trace[i], frame.String())
+		}
+	}
+}
+
+func TestFieldsNoStackTrace(t *testing.T) {
+	if fields := tracerr.Fields(nil); fields != nil {
+		t.Errorf("tracerr.Fields(nil) = %#v; want nil", fields)
+	}
+	fields := tracerr.Fields(errors.New("plain error"))
+	if len(fields) != 1 || fields["error"] != "plain error" {
+		t.Errorf("tracerr.Fields(plain) = %#v; want only error field", fields)
+	}
+}
+
+func TestFrameFields(t *testing.T) {
+	err := addFrameA("frame fields error")
+	fields := tracerr.FrameFields(err)
+	frames := tracerr.StackTrace(err)
+	if len(fields) != len(frames) {
+		t.Fatalf("len(fields) = %#v; want %#v", len(fields), len(frames))
+	}
+	for i, frame := range frames {
+		if fields[i]["func"] != frame.Func {
+			t.Errorf("fields[%#v][\"func\"] = %#v; want %#v", i, fields[i]["func"], frame.Func)
+		}
+		if fields[i]["file"] != frame.Path {
+			t.Errorf("fields[%#v][\"file\"] = %#v; want %#v", i, fields[i]["file"], frame.Path)
+		}
+		if fields[i]["line"] != frame.Line {
+			t.Errorf("fields[%#v][\"line\"] = %#v; want %#v", i, fields[i]["line"], frame.Line)
+		}
+	}
+	if fields[0]["line"] != 17 {
+		t.Errorf("fields[0][\"line\"] = %#v; want %#v", fields[0]["line"], 17)
+	}
+	if fields := tracerr.FrameFields(errors.New("plain error")); fields != nil {
+		t.Errorf("tracerr.FrameFields(plain) = %#v; want nil", fields)
+	}
+}
@@ -0,0 +1,112 @@
+package tracerr
+
+import (
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+var filterMutex sync.RWMutex
+
+var frameFilter func(Frame) bool
+
+// SetFrameFilter sets a function, which defines frames to display.
+// Frame is dropped from output if filter returns false.
+// Pass nil to display all frames.
+//
+// Filter is applied before DefaultMaxFrames cap, so only kept frames are counted.
+func SetFrameFilter(filter func(Frame) bool) {
+	filterMutex.Lock()
+	defer filterMutex.Unlock()
+	frameFilter = filter
+}
+
+func getFrameFilter() func(Frame) bool {
+	filterMutex.RLock()
+	defer filterMutex.RUnlock()
+	return frameFilter
+}
+
+var frameTransform func(Frame) Frame
+
+// SetFrameTransform sets a function, which rewrites each frame before it's displayed,
+// such as to map vendored paths to upstream ones or to anonymize function names.
+// It's applied after filter set by SetFrameFilter.
+// Source fragment is read by path of transformed frame,
+// so keep Path unchanged to display the same source.
+// Pass nil to display frames as they are.
+//
+// It affects only output, stack trace stays the same.
+func SetFrameTransform(transform func(Frame) Frame) {
+	filterMutex.Lock()
+	defer filterMutex.Unlock()
+	frameTransform = transform
+}
+
+func getFrameTransform() func(Frame) Frame {
+	filterMutex.RLock()
+	defer filterMutex.RUnlock()
+	return frameTransform
+}
+
+// ExcludePackages returns a frame filter, which drops frames of functions
+// from any of packages with provided import path prefixes,
+// such as "runtime" or "net/http".
+// Subpackages are excluded as well.
+func ExcludePackages(prefixes ...string) func(Frame) bool {
+	return func(frame Frame) bool {
+		for _, prefix := range prefixes {
+			if inPackage(frame.Func, prefix) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// OnlyPackages returns a frame filter, which keeps only frames of functions
+// from any of packages with provided import path prefixes,
+// or frames with file path starting with any of prefixes,
+// such as "github.com/john/doe" or "/home/john/doe".
+// Subpackages are kept as well.
+//
+// If no prefixes are provided, path of the main module is used.
+// All frames are kept if main module is unknown.
+func OnlyPackages(prefixes ...string) func(Frame) bool {
+	if len(prefixes) == 0 {
+		module := mainModule()
+		if module == "" {
+			return func(Frame) bool {
+				return true
+			}
+		}
+		prefixes = []string{module}
+	}
+	return func(frame Frame) bool {
+		for _, prefix := range prefixes {
+			if inPackage(frame.Func, prefix) || strings.HasPrefix(frame.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// mainModule returns path of the main module from build info,
+// or empty string if it's not available.
+func mainModule() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	return info.Main.Path
+}
+
+// inPackage reports whether function fn is in package or subpackage of prefix.
+func inPackage(fn, prefix string) bool {
+	if !strings.HasPrefix(fn, prefix) {
+		return false
+	}
+	rest := fn[len(prefix):]
+	return rest == "" || rest[0] == '.' || rest[0] == '/'
+}
@@ -0,0 +1,172 @@
+package tracerr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestSetFrameFilter(t *testing.T) {
+	defer tracerr.SetFrameFilter(nil)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{Func: "main.foo", Line: 1, Path: "/src/main.go"},
+			{Func: "runtime.gopanic", Line: 2, Path: "/go/src/runtime/panic.go"},
+			{Func: "net/http.HandlerFunc.ServeHTTP", Line: 3, Path: "/go/src/net/http/server.go"},
+			{Func: "net/http/httputil.(*ReverseProxy).ServeHTTP", Line: 4, Path: "/go/src/net/http/httputil/proxy.go"},
+			{Func: "net/httpx.Serve", Line: 5, Path: "/src/httpx/serve.go"},
+			{Func: "main.bar", Line: 6, Path: "/src/main.go"},
+			{Func: "main.main", Line: 7, Path: "/src/main.go"},
+		},
+	)
+
+	tracerr.SetFrameFilter(tracerr.ExcludePackages("runtime", "net/http"))
+	expected := strings.Join([]string{
+		"some error",
+		"/src/main.go:1 main.foo()",
+		"/src/httpx/serve.go:5 net/httpx.Serve()",
+		"/src/main.go:6 main.bar()",
+		"/src/main.go:7 main.main()",
+	}, "\n")
+	output := tracerr.Sprint(err)
+	if output != expected {
+		t.Errorf("tracerr.Sprint(err) = %#v; want %#v", output, expected)
+	}
+
+	// Cap counts only kept frames.
+	tracerr.SetFrameFilter(func(frame tracerr.Frame) bool {
+		return !strings.Contains(frame.Path, "/go/src/")
+	})
+	expected = strings.Join([]string{
+		"some error",
+		"/src/main.go:1 main.foo()",
+		"/src/httpx/serve.go:5 net/httpx.Serve()",
+		"/src/main.go:6 main.bar()",
+	}, "\n")
+	output = tracerr.SprintWithOptions(err, tracerr.WithMaxFrames(3))
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetFrameFilter(nil)
+	if rows := strings.Split(tracerr.Sprint(err), "\n"); len(rows) != 8 {
+		t.Errorf("len(rows) = %#v; want %#v", len(rows), 8)
+	}
+}
+
+func TestWithOnlyPackages(t *testing.T) {
+	defer tracerr.SetFrameFilter(nil)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{Func: "github.com/vendor/lib.Do", Line: 1, Path: "/go/pkg/mod/github.com/vendor/lib@v1.0.0/lib.go"},
+			{Func: "github.com/john/doe/store.(*DB).Get", Line: 2, Path: "/src/doe/store/db.go"},
+			{Func: "github.com/john/doex.Run", Line: 3, Path: "/src/doex/run.go"},
+			{Func: "github.com/john/doe.handle", Line: 4, Path: "/src/doe/handle.go"},
+			{Func: "net/http.HandlerFunc.ServeHTTP", Line: 5, Path: "/go/src/net/http/server.go"},
+			{Func: "main.main", Line: 6, Path: "/src/doe/cmd/main.go"},
+			{Func: "runtime.main", Line: 7, Path: "/go/src/runtime/proc.go"},
+		},
+	)
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithOnlyPackages("github.com/john/doe"))
+	expected := strings.Join([]string{
+		"some error",
+		"/src/doe/store/db.go:2 github.com/john/doe/store.(*DB).Get()",
+		"/src/doe/handle.go:4 github.com/john/doe.handle()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err) = %#v; want %#v", output, expected)
+	}
+
+	// Path prefix and cap after filtering.
+	output = tracerr.SprintWithOptions(
+		err,
+		tracerr.WithOnlyPackages("/src/doe/"),
+		tracerr.WithMaxFrames(2),
+	)
+	expected = strings.Join([]string{
+		"some error",
+		"/src/doe/store/db.go:2 github.com/john/doe/store.(*DB).Get()",
+		"/src/doe/handle.go:4 github.com/john/doe.handle()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err) = %#v; want %#v", output, expected)
+	}
+
+	// Combined with global filter.
+	tracerr.SetFrameFilter(tracerr.ExcludePackages("github.com/john/doe/store"))
+	output = tracerr.SprintWithOptions(err, tracerr.WithOnlyPackages("github.com/john/doe", "main"))
+	expected = strings.Join([]string{
+		"some error",
+		"/src/doe/handle.go:4 github.com/john/doe.handle()",
+		"/src/doe/cmd/main.go:6 main.main()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err) = %#v; want %#v", output, expected)
+	}
+}
+
+func TestOnlyPackagesMainModule(t *testing.T) {
+	only := tracerr.OnlyPackages()
+	frames := []tracerr.Frame{
+		{Func: "github.com/ztrue/tracerr.New"},
+		{Func: "github.com/ztrue/tracerr/internal.Foo"},
+		{Func: "github.com/vendor/lib.Do"},
+		{Func: "runtime.main"},
+	}
+	expected := []bool{true, true, false, false}
+	for i, frame := range frames {
+		if kept := only(frame); kept != expected[i] {
+			t.Errorf("only(frames[%#v]) = %#v; want %#v", i, kept, expected[i])
+		}
+	}
+}
+
+func TestSetFrameTransform(t *testing.T) {
+	defer tracerr.SetFrameFilter(nil)
+	defer tracerr.SetFrameTransform(nil)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{Func: "main.foo", Line: 1, Path: "/src/main.go"},
+			{Func: "runtime.main", Line: 2, Path: "/go/src/runtime/proc.go"},
+			{Func: "main.main", Line: 3, Path: "/src/main.go"},
+		},
+	)
+
+	var transformed []string
+	tracerr.SetFrameFilter(tracerr.ExcludePackages("runtime"))
+	tracerr.SetFrameTransform(func(frame tracerr.Frame) tracerr.Frame {
+		transformed = append(transformed, frame.Func)
+		frame.Path = "https://example.com/repo" + frame.Path
+		return frame
+	})
+	output := tracerr.SprintSource(err, 0, 0)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"https://example.com/repo/src/main.go:1 main.foo()",
+		"tracerr: file https://example.com/repo/src/main.go not found",
+		"",
+		"https://example.com/repo/src/main.go:3 main.main()",
+		"tracerr: file https://example.com/repo/src/main.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want %#v", output, expected)
+	}
+	if strings.Join(transformed, ",") != "main.foo,main.main" {
+		t.Errorf("transformed = %#v; want filtered frames only", transformed)
+	}
+	if path := err.StackTrace()[0].Path; path != "/src/main.go" {
+		t.Errorf("err.StackTrace()[0].Path = %#v; want unchanged", path)
+	}
+
+	tracerr.SetFrameTransform(nil)
+	if output := tracerr.Sprint(err); !strings.Contains(output, "\n/src/main.go:1 main.foo()") {
+		t.Errorf("tracerr.Sprint(err) = %#v; want original frames", output)
+	}
+}
@@ -0,0 +1,66 @@
+package tracerr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+)
+
+// DefaultFingerprintFrames is a default number of innermost frames used by Fingerprint.
+const DefaultFingerprintFrames = 3
+
+var fingerprintFrames atomic.Int64
+
+var fingerprintRawMessage atomic.Bool
+
+func init() {
+	fingerprintFrames.Store(DefaultFingerprintFrames)
+}
+
+// SetFingerprintFrames sets a number of innermost frames used by Fingerprint,
+// DefaultFingerprintFrames by default.
+// More frames tell apart the same error returned by different callers.
+// If n <= 0 only error message is used.
+func SetFingerprintFrames(n int) {
+	fingerprintFrames.Store(int64(n))
+}
+
+// SetFingerprintNormalize defines whether numbers, hex values and UUIDs
+// in error message are replaced before it's used by Fingerprint, which is a default.
+// Disable it to tell apart errors by all their message.
+func SetFingerprintNormalize(enabled bool) {
+	fingerprintRawMessage.Store(!enabled)
+}
+
+// dynamicPattern matches UUIDs, words of digits and hex letters with at least one digit
+// and any other digits, such as ids, counters, addresses and durations.
+var dynamicPattern = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b|\b(0x)?[0-9a-fA-F]*[0-9][0-9a-fA-F]*\b|[0-9]+`)
+
+// Fingerprint returns a short hex hash of err to group the same errors together,
+// such as in error tracking dashboards.
+// It's made of error message and function, path and line of a few innermost frames,
+// so it's stable across runs of the same binary.
+// Dynamic data in message, such as numbers, is normalized,
+// so errors created at the same place with different values share a fingerprint.
+// It returns an empty string if err is nil.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+	message := err.Error()
+	if !fingerprintRawMessage.Load() {
+		message = dynamicPattern.ReplaceAllString(message, "#")
+	}
+	h := sha256.New()
+	h.Write([]byte(message))
+	frames := StackTrace(err)
+	if n := int(fingerprintFrames.Load()); len(frames) > n {
+		frames = frames[:max(n, 0)]
+	}
+	for _, frame := range frames {
+		h.Write([]byte("\n" + frame.Func + "\n" + frame.Path + ":" + strconv.Itoa(frame.Line)))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
@@ -0,0 +1,64 @@
+package tracerr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func fingerprintError(id int) error {
+	return tracerr.Errorf("user %d not found", id)
+}
+
+func otherFingerprintError(id int) error {
+	return tracerr.Errorf("user %d not found", id)
+}
+
+type FingerprintTestCase struct {
+	A        error
+	B        error
+	Expected bool
+}
+
+func TestFingerprint(t *testing.T) {
+	defer tracerr.SetFingerprintFrames(tracerr.DefaultFingerprintFrames)
+	defer tracerr.SetFingerprintNormalize(true)
+
+	cases := []FingerprintTestCase{
+		{fingerprintError(1), fingerprintError(42), true},
+		{errors.New("id 7f3a91 at 0xc000123 failed"), errors.New("id 0c12ee at 0xc000456 failed"), true},
+		{fingerprintError(1), otherFingerprintError(1), false},
+		{errors.New("timeout after 5s"), errors.New("timeout after 10s"), true},
+		{errors.New("request 3f2504e0-4f89-11d3-9a0c-0305e82c3301 failed"), errors.New("request 6ba7b810-9dad-11d1-80b4-00c04fd430c8 failed"), true},
+		{errors.New("read failed"), errors.New("write failed"), false},
+	}
+	for i, c := range cases {
+		a, b := tracerr.Fingerprint(c.A), tracerr.Fingerprint(c.B)
+		if len(a) != 16 {
+			t.Errorf("case %#v: tracerr.Fingerprint(a) = %#v; want 16 hex chars", i, a)
+		}
+		if (a == b) != c.Expected {
+			t.Errorf("case %#v: tracerr.Fingerprint(a) == tracerr.Fingerprint(b) = %#v; want %#v", i, a == b, c.Expected)
+		}
+	}
+
+	if fingerprint := tracerr.Fingerprint(nil); fingerprint != "" {
+		t.Errorf("tracerr.Fingerprint(nil) = %#v; want %#v", fingerprint, "")
+	}
+
+	tracerr.SetFingerprintNormalize(false)
+	if tracerr.Fingerprint(fingerprintError(1)) == tracerr.Fingerprint(fingerprintError(42)) {
+		t.Errorf("tracerr.Fingerprint() with no normalization is equal for different messages")
+	}
+	tracerr.SetFingerprintNormalize(true)
+
+	tracerr.SetFingerprintFrames(0)
+	if tracerr.Fingerprint(fingerprintError(1)) != tracerr.Fingerprint(otherFingerprintError(1)) {
+		t.Errorf("tracerr.Fingerprint() with no frames differs for the same message")
+	}
+	if tracerr.Fingerprint(fingerprintError(1)) != tracerr.Fingerprint(fmt.Errorf("user %d not found", 1)) {
+		t.Errorf("tracerr.Fingerprint() with no frames differs from error with no stack trace")
+	}
+}
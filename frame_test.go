@@ -0,0 +1,171 @@
+package tracerr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+type FrameNameTestCase struct {
+	Func            string
+	ExpectedPackage string
+	ExpectedName    string
+}
+
+func TestFrameFuncName(t *testing.T) {
+	cases := []FrameNameTestCase{
+		{"main.main", "main", "main"},
+		{"github.com/john/doe.Foo", "github.com/john/doe", "Foo"},
+		{"github.com/john/doe.(*T).Method", "github.com/john/doe", "(*T).Method"},
+		{"github.com/john/doe.T.Method", "github.com/john/doe", "T.Method"},
+		{"github.com/john/doe.Foo.func1", "github.com/john/doe", "Foo.func1"},
+		{"github.com/john/doe.Foo.func1.2", "github.com/john/doe", "Foo.func1.2"},
+		{"github.com/john/doe.Map[...]", "github.com/john/doe", "Map[...]"},
+		{"gopkg.in/yaml%2ev3.Unmarshal", "gopkg.in/yaml%2ev3", "Unmarshal"},
+		{"runtime.goexit", "runtime", "goexit"},
+		{"unknown", "", "unknown"},
+		{"", "", ""},
+	}
+	for i, c := range cases {
+		frame := tracerr.Frame{Func: c.Func}
+		if pkg := frame.Package(); pkg != c.ExpectedPackage {
+			t.Errorf("cases[%#v].Package() = %#v; want %#v", i, pkg, c.ExpectedPackage)
+		}
+		if name := frame.FuncName(); name != c.ExpectedName {
+			t.Errorf("cases[%#v].FuncName() = %#v; want %#v", i, name, c.ExpectedName)
+		}
+	}
+}
+
+type frameNameReceiver struct{}
+
+func (*frameNameReceiver) newError() error {
+	return func() error {
+		return tracerr.New("frame name error")
+	}()
+}
+
+func TestFrameFuncNameCaptured(t *testing.T) {
+	err := (&frameNameReceiver{}).newError()
+	frames := tracerr.StackTrace(err)
+	if len(frames) < 2 {
+		t.Fatalf("len(frames) = %#v; want at least 2", len(frames))
+	}
+	expected := []string{
+		"(*frameNameReceiver).newError.func1",
+		"(*frameNameReceiver).newError",
+	}
+	for i, name := range expected {
+		if frames[i].FuncName() != name {
+			t.Errorf("frames[%#v].FuncName() = %#v; want %#v", i, frames[i].FuncName(), name)
+		}
+		if frames[i].Package() != "github.com/ztrue/tracerr_test" {
+			t.Errorf("frames[%#v].Package() = %#v; want %#v", i, frames[i].Package(), "github.com/ztrue/tracerr_test")
+		}
+	}
+}
+
+func originError() error {
+	return tracerr.New("some error")
+}
+
+func TestOrigin(t *testing.T) {
+	frame, ok := tracerr.Origin(originError())
+	if !ok {
+		t.Fatalf("tracerr.Origin(err) ok = false; want true")
+	}
+	if frame.Func != "github.com/ztrue/tracerr_test.originError" {
+		t.Errorf("frame.Func = %#v; want %#v", frame.Func, "github.com/ztrue/tracerr_test.originError")
+	}
+
+	defer tracerr.SetDefaultIgnoreFrames(0, 0)
+	tracerr.SetDefaultIgnoreFrames(1, 0)
+	frame, ok = tracerr.Origin(originError())
+	if !ok || frame.Func != "github.com/ztrue/tracerr_test.TestOrigin" {
+		t.Errorf("tracerr.Origin(err) = %#v, %#v; want TestOrigin frame", frame, ok)
+	}
+
+	empty := tracerr.CustomError(errors.New("some error"), nil)
+	if frame, ok := tracerr.Origin(empty); ok {
+		t.Errorf("tracerr.Origin(empty) = %#v, %#v; want false", frame, ok)
+	}
+	if frame, ok := tracerr.Origin(errors.New("some error")); ok {
+		t.Errorf("tracerr.Origin(err) = %#v, %#v; want false", frame, ok)
+	}
+	if frame, ok := tracerr.Origin(nil); ok {
+		t.Errorf("tracerr.Origin(nil) = %#v, %#v; want false", frame, ok)
+	}
+}
+
+type HasStackTestCase struct {
+	Err      error
+	Expected bool
+}
+
+func TestHasStack(t *testing.T) {
+	plain := errors.New("some error")
+	cases := []HasStackTestCase{
+		{Err: nil, Expected: false},
+		{Err: plain, Expected: false},
+		{Err: fmt.Errorf("context: %w", plain), Expected: false},
+		{Err: tracerr.New("some error"), Expected: true},
+		{Err: tracerr.Wrap(plain), Expected: true},
+		{Err: fmt.Errorf("context: %w", tracerr.Wrap(plain)), Expected: true},
+		{Err: tracerr.CustomError(plain, nil), Expected: false},
+		{Err: tracerr.CustomError(tracerr.New("some error"), nil), Expected: true},
+		{Err: tracerr.Join(plain, errors.New("other error")), Expected: true},
+		{Err: tracerr.Wrap(tracerr.Join(plain, errors.New("other error"))), Expected: true},
+		{Err: fmt.Errorf("context: %w", errors.Join(plain, tracerr.New("some error"))), Expected: true},
+		{Err: errors.Join(plain, errors.New("other error")), Expected: false},
+	}
+	for i, c := range cases {
+		if hasStack := tracerr.HasStack(c.Err); hasStack != c.Expected {
+			t.Errorf("cases[%#v]: tracerr.HasStack(err) = %#v; want %#v", i, hasStack, c.Expected)
+		}
+	}
+}
+
+type FrameCountTestCase struct {
+	Err      error
+	Expected int
+}
+
+func TestFrameCount(t *testing.T) {
+	plain := errors.New("some error")
+	custom := tracerr.CustomError(plain, []tracerr.Frame{
+		{Func: "main.a", Line: 10, Path: "/src/main.go"},
+		{Func: "main.b", Line: 20, Path: "/src/main.go"},
+		{Func: "main.main", Line: 30, Path: "/src/main.go"},
+	})
+	cases := []FrameCountTestCase{
+		{Err: nil, Expected: 0},
+		{Err: plain, Expected: 0},
+		{Err: custom, Expected: 3},
+		{Err: fmt.Errorf("context: %w", custom), Expected: 3},
+		{Err: tracerr.CustomError(plain, nil), Expected: 0},
+	}
+	for i, c := range cases {
+		if count := tracerr.FrameCount(c.Err); count != c.Expected {
+			t.Errorf("cases[%#v]: tracerr.FrameCount(err) = %#v; want %#v", i, count, c.Expected)
+		}
+	}
+
+	defer tracerr.SetDefaultMaxFrames(0)
+	defer tracerr.SetDefaultIgnoreFrames(0, 0)
+	tracerr.SetDefaultMaxFrames(2)
+	tracerr.SetDefaultIgnoreFrames(1, 1)
+	err := addFrameA("deep error")
+	frames := tracerr.StackTrace(err)
+	if count := tracerr.FrameCount(err); count != len(frames) {
+		t.Errorf("tracerr.FrameCount(err) = %#v; want %#v", count, len(frames))
+	}
+	// addFrameC, addFrameB, addFrameA, TestFrameCount and frames of test runner.
+	if count := tracerr.FrameCount(err); count < 5 {
+		t.Errorf("tracerr.FrameCount(err) = %#v; want at least 5", count)
+	}
+	if frames[3].Func != "github.com/ztrue/tracerr_test.TestFrameCount" {
+		t.Errorf("frames[3].Func = %#v; want %#v", frames[3].Func, "github.com/ztrue/tracerr_test.TestFrameCount")
+	}
+}
@@ -0,0 +1,63 @@
+package tracerr
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+var captureGoroutineID atomic.Bool
+
+// SetCaptureGoroutineID defines whether ID of goroutine is captured on error creation,
+// it's disabled by default.
+//
+// ID is parsed from runtime.Stack header, which adds an overhead to every new error.
+func SetCaptureGoroutineID(enabled bool) {
+	captureGoroutineID.Store(enabled)
+}
+
+// goroutineID returns ID of current goroutine or 0 if capturing is disabled.
+func goroutineID() int {
+	if !captureGoroutineID.Load() {
+		return 0
+	}
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	// Stack starts with "goroutine 42 [running]:".
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+	id, err := strconv.Atoi(string(buf))
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// allGoroutinesBufferSize is an initial buffer size for dump of all goroutines.
+const allGoroutinesBufferSize = 64 << 10
+
+// SprintAllGoroutines returns error output the same way as Sprint,
+// followed by stack traces of all goroutines, such as to find a goroutine leak
+// or a deadlock an error coincides with.
+//
+// Dump is taken with runtime.Stack, which stops the world,
+// so it's expensive and should be used only for rare errors.
+// It starts with 64 KB buffer, which is doubled until the whole dump fits.
+func SprintAllGoroutines(err error) string {
+	return Sprint(err) + "\n\n--- all goroutines ---\n\n" + allGoroutines()
+}
+
+// allGoroutines returns stack traces of all goroutines.
+func allGoroutines() string {
+	buf := make([]byte, allGoroutinesBufferSize)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(bytes.TrimRight(buf[:n], "\n"))
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
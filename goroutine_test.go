@@ -0,0 +1,82 @@
+package tracerr_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestGoroutineID(t *testing.T) {
+	defer tracerr.SetCaptureGoroutineID(false)
+
+	err := tracerr.New("some error")
+	if tracerr.GoroutineID(err) != 0 {
+		t.Errorf("tracerr.GoroutineID(err) = %#v; want 0", tracerr.GoroutineID(err))
+	}
+	// Message and frames only, with no goroutine row.
+	if rows := strings.Split(tracerr.Sprint(err), "\n"); len(rows) != len(err.StackTrace())+1 {
+		t.Errorf("tracerr.Sprint(err) = %#v; want no goroutine", tracerr.Sprint(err))
+	}
+
+	tracerr.SetCaptureGoroutineID(true)
+	type result struct {
+		err      tracerr.Error
+		expected int
+	}
+	results := make(chan result)
+	go func() {
+		buf := make([]byte, 64)
+		buf = buf[:runtime.Stack(buf, false)]
+		var expected int
+		fmt.Sscanf(string(buf), "goroutine %d ", &expected)
+		results <- result{
+			err:      tracerr.New("some error"),
+			expected: expected,
+		}
+	}()
+	r := <-results
+	if r.expected == 0 || tracerr.GoroutineID(r.err) != r.expected {
+		t.Errorf("tracerr.GoroutineID(err) = %#v; want %#v", tracerr.GoroutineID(r.err), r.expected)
+	}
+	rows := strings.Split(tracerr.Sprint(r.err), "\n")
+	expectedRow := fmt.Sprintf("goroutine %d", r.expected)
+	if rows[1] != expectedRow {
+		t.Errorf("rows[1] = %#v; want %#v", rows[1], expectedRow)
+	}
+
+	wrapped := tracerr.Wrap(fmt.Errorf("some error"))
+	if tracerr.GoroutineID(wrapped) == 0 {
+		t.Errorf("tracerr.GoroutineID(tracerr.Wrap(err)) = 0; want > 0")
+	}
+}
+
+func TestSprintAllGoroutines(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	started := make(chan struct{})
+	go func() {
+		close(started)
+		waitForStop(stop)
+	}()
+	<-started
+
+	err := tracerr.New("some error")
+	output := tracerr.SprintAllGoroutines(err)
+	prefix := tracerr.Sprint(err) + "\n\n--- all goroutines ---\n\ngoroutine "
+	if !strings.HasPrefix(output, prefix) {
+		t.Errorf("tracerr.SprintAllGoroutines(err) = %#v; want prefix %#v", output, prefix)
+	}
+	if !strings.Contains(output, "waitForStop") {
+		t.Errorf("tracerr.SprintAllGoroutines(err) = %#v; want other goroutine", output)
+	}
+	if strings.HasSuffix(output, "\n") {
+		t.Errorf("tracerr.SprintAllGoroutines(err) = %#v; want no trailing newline", output)
+	}
+}
+
+func waitForStop(stop chan struct{}) {
+	<-stop
+}
@@ -0,0 +1,99 @@
+package tracerr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// groupError is an error that aggregates multiple errors,
+// grouped by the origin of their stack traces.
+type groupError struct {
+	errs   []error
+	groups [][]error
+}
+
+// groupKey identifies errors with the same origin.
+type groupKey struct {
+	fn    string
+	path  string
+	line  int
+	cause string
+}
+
+// Group returns an error that wraps all non-nil errs, the same way as Join does,
+// so errors without stack trace get one added by Wrap, but errors are grouped by the innermost frame of their stack traces,
+// or by message of their Cause if there is no stack trace.
+//
+// Output contains each group once, with a number of errors in it
+// and a stack trace of the first one, followed by distinct messages of the group.
+//
+// Group returns nil if all errs are nil,
+// and the same as Wrap if there is only one non-nil error.
+func Group(errs ...error) error {
+	grouped := &groupError{}
+	index := map[groupKey]int{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		key := originKey(err)
+		err = wrapMember(err)
+		grouped.errs = append(grouped.errs, err)
+		i, ok := index[key]
+		if !ok {
+			i = len(grouped.groups)
+			index[key] = i
+			grouped.groups = append(grouped.groups, nil)
+		}
+		grouped.groups[i] = append(grouped.groups[i], err)
+	}
+	switch len(grouped.errs) {
+	case 0:
+		return nil
+	case 1:
+		return grouped.errs[0]
+	}
+	return grouped
+}
+
+func originKey(err error) groupKey {
+	if frames := StackTrace(err); len(frames) > 0 {
+		return groupKey{fn: frames[0].Func, path: frames[0].Path, line: frames[0].Line}
+	}
+	return groupKey{cause: Cause(err).Error()}
+}
+
+// Error returns messages of all errors separated by newline,
+// the same way as errors.Join does.
+func (e *groupError) Error() string {
+	messages := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns grouped errors.
+func (e *groupError) Unwrap() []error {
+	return e.errs
+}
+
+func sprintGroup(e *groupError, o *options) string {
+	entries := make([]string, 0, len(e.groups))
+	for _, group := range e.groups {
+		rows := []string{fmt.Sprintf("(×%d) %s", len(group), sprint(group[0], o))}
+		var messages []string
+		seen := map[string]bool{}
+		for _, err := range group {
+			if message := err.Error(); !seen[message] {
+				seen[message] = true
+				messages = append(messages, "- "+message)
+			}
+		}
+		if len(messages) > 1 {
+			rows = append(rows, messages...)
+		}
+		entries = append(entries, strings.Join(rows, "\n"))
+	}
+	return sprintEntries(fmt.Sprintf("%d errors occurred in %d groups:", len(e.errs), len(e.groups)), entries, o)
+}
@@ -0,0 +1,95 @@
+package tracerr_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func stormError(i int) error {
+	return tracerr.Errorf("request %d timed out", i%3)
+}
+
+func TestGroup(t *testing.T) {
+	errs := make([]error, 0, 103)
+	for i := 0; i < 100; i++ {
+		errs = append(errs, stormError(i))
+	}
+	distinct := errors.New("distinct error")
+	errs = append(errs, nil, tracerr.New("other error"), distinct, errors.New("distinct error"))
+	err := tracerr.Group(errs...)
+
+	if !errors.Is(err, distinct) {
+		t.Errorf("errors.Is(err, distinct) = false; want true")
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 103 {
+		t.Errorf("len(err.Unwrap()) = %#v; want %#v", n, 103)
+	}
+
+	rows := strings.Split(tracerr.SprintWithOptions(err, tracerr.WithMaxFrames(1)), "\n")
+	expectedPrefixes := []string{
+		"103 errors occurred in 3 groups:",
+		"",
+		"(×100) request 0 timed out",
+		"group_test.go:13 github.com/ztrue/tracerr_test.stormError()",
+		"- request 0 timed out",
+		"- request 1 timed out",
+		"- request 2 timed out",
+		"",
+		"(×1) other error",
+		"group_test.go:",
+		"",
+		"(×2) distinct error",
+		"group_test.go:",
+	}
+	if len(rows) != len(expectedPrefixes) {
+		t.Fatalf("len(rows) = %#v; want %#v: %#v", len(rows), len(expectedPrefixes), rows)
+	}
+	for i, prefix := range expectedPrefixes {
+		row := rows[i]
+		if j := strings.Index(row, "/tracerr/"); j >= 0 && strings.HasPrefix(prefix, "group_test.go") {
+			row = row[j+len("/tracerr/"):]
+		}
+		if !strings.HasPrefix(row, prefix) {
+			t.Errorf("rows[%#v] = %#v; want prefix %#v", i, row, prefix)
+		}
+	}
+}
+
+func TestGroupSingle(t *testing.T) {
+	if err := tracerr.Group(nil, nil); err != nil {
+		t.Errorf("tracerr.Group(nil, nil) = %#v; want nil", err)
+	}
+	err := errors.New("some error")
+	grouped := tracerr.Group(nil, err)
+	if tracerr.Unwrap(grouped) != err || len(tracerr.StackTrace(grouped)) == 0 {
+		t.Errorf("tracerr.Group(nil, err) = %#v; want err with stack trace", grouped)
+	}
+	if msg := tracerr.Group(err, fmt.Errorf("other")).Error(); msg != "some error\nother" {
+		t.Errorf("tracerr.Group(...).Error() = %#v; want %#v", msg, "some error\nother")
+	}
+}
+
+func TestGroupCreated(t *testing.T) {
+	defer tracerr.ResetCreateHooks()
+	defer tracerr.SetErrorHistorySize(0)
+	tracerr.SetErrorHistorySize(10)
+	var created []string
+	tracerr.OnCreate(func(e tracerr.Error) {
+		created = append(created, e.Error())
+	})
+	err := tracerr.Group(errors.New("first error"), errors.New("second error"), tracerr.New("traced error"))
+	expected := []string{"traced error", "first error", "second error"}
+	if strings.Join(created, ",") != strings.Join(expected, ",") {
+		t.Errorf("created = %#v; want %#v", created, expected)
+	}
+	if recent := tracerr.RecentErrors(); len(recent) != 3 {
+		t.Errorf("len(tracerr.RecentErrors()) = %#v; want %#v", len(recent), 3)
+	}
+	if err == nil {
+		t.Errorf("tracerr.Group(...) = nil; want error")
+	}
+}
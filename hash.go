@@ -0,0 +1,88 @@
+package tracerr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var hashMutex sync.Mutex
+
+var sourceHashes map[string]string
+
+// hashMismatches keeps result of hash comparison by frame path,
+// so files are hashed once until hashes or source cache are reset.
+var hashMismatches = map[string]bool{}
+
+// SetSourceHashes sets hex encoded SHA-256 hashes of source files, recorded when binary was built.
+// Source fragment of a file, which hash differs, is preceded by a warning,
+// since it may not match frame lines.
+// Files with no hash are not checked.
+//
+// Keys are either frame paths or paths relative to any parent directory of them,
+// such as "pkg/main.go" for "/src/app/pkg/main.go", so output of sha256sum fits.
+// Pass nil to disable the check.
+func SetSourceHashes(hashes map[string]string) {
+	copied := make(map[string]string, len(hashes))
+	for path, hash := range hashes {
+		copied[filepath.ToSlash(strings.TrimPrefix(path, "./"))] = strings.ToLower(hash)
+	}
+	hashMutex.Lock()
+	defer hashMutex.Unlock()
+	sourceHashes = copied
+	hashMismatches = map[string]bool{}
+}
+
+// clearHashMismatches forgets compared hashes, so files are hashed again.
+func clearHashMismatches() {
+	hashMutex.Lock()
+	defer hashMutex.Unlock()
+	hashMismatches = map[string]bool{}
+}
+
+// sourceHashMismatch reports whether file hash differs from the one set by SetSourceHashes.
+// Files, which could not be read, are not reported, since there is no source to warn about.
+func sourceHashMismatch(path string) bool {
+	hashMutex.Lock()
+	hashes := sourceHashes
+	mismatches := hashMismatches
+	cached, ok := mismatches[path]
+	hashMutex.Unlock()
+	if len(hashes) == 0 {
+		return false
+	}
+	if ok {
+		return cached
+	}
+	// File is read and hashed with no lock, so other frames are not blocked.
+	mismatch := false
+	if expected, ok := lookupHash(hashes, path); ok {
+		if b, err := readSource(path); err == nil {
+			sum := sha256.Sum256(b)
+			mismatch = hex.EncodeToString(sum[:]) != expected
+		}
+	}
+	hashMutex.Lock()
+	defer hashMutex.Unlock()
+	// Result is dropped if hashes are reset meanwhile, since mismatches are replaced.
+	mismatches[path] = mismatch
+	return mismatch
+}
+
+// lookupHash finds hash by frame path or by the longest relative path it ends with.
+func lookupHash(hashes map[string]string, path string) (string, bool) {
+	slashed := filepath.ToSlash(path)
+	if hash, ok := hashes[slashed]; ok {
+		return hash, true
+	}
+	found := ""
+	hash := ""
+	for key, value := range hashes {
+		if len(key) > len(found) && strings.HasSuffix(slashed, "/"+key) {
+			found, hash = key, value
+		}
+	}
+	return hash, found != ""
+}
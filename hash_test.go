@@ -0,0 +1,91 @@
+package tracerr_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ztrue/tracerr"
+)
+
+type SourceHashesTestCase struct {
+	Hashes  map[string]string
+	Warning bool
+}
+
+func TestSetSourceHashes(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	defer tracerr.SetSourceHashes(nil)
+	data := []byte("package main")
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/app/main.go": {Data: data},
+	})
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	err := sourceFileError("/src/app/main.go")
+	warning := "tracerr: file /src/app/main.go does not match hash recorded at build time, source may not match"
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"/src/app/main.go:1 main.Foo()",
+		warning,
+		"1\tpackage main",
+	}, "\n")
+
+	cases := []SourceHashesTestCase{
+		{nil, false},
+		{map[string]string{"/src/app/main.go": hash}, false},
+		{map[string]string{"./app/main.go": strings.ToUpper(hash)}, false},
+		{map[string]string{"/src/app/other.go": "0000"}, false},
+		{map[string]string{"/src/app/main.go": "0000"}, true},
+		{map[string]string{"main.go": "0000"}, true},
+		{map[string]string{"main.go": "0000", "app/main.go": hash}, false},
+	}
+	for _, c := range cases {
+		tracerr.SetSourceHashes(c.Hashes)
+		output := tracerr.SprintSource(err)
+		if c.Warning && output != expected {
+			t.Errorf("tracerr.SprintSource(err) with hashes %#v = %#v; want %#v", c.Hashes, output, expected)
+		}
+		if !c.Warning && strings.Contains(output, warning) {
+			t.Errorf("tracerr.SprintSource(err) with hashes %#v = %#v; want no warning", c.Hashes, output)
+		}
+	}
+}
+
+func TestSetSourceHashesConcurrent(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	defer tracerr.SetSourceHashes(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/app/main.go": {Data: []byte("package main")},
+	})
+	err := sourceFileError("/src/app/main.go")
+	warning := "does not match hash recorded at build time"
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			tracerr.SprintSource(err)
+		}()
+		go func() {
+			defer wg.Done()
+			tracerr.SetSourceHashes(map[string]string{"main.go": "0000"})
+		}()
+	}
+	wg.Wait()
+
+	// Result computed for replaced hashes is not kept.
+	tracerr.SetSourceHashes(nil)
+	tracerr.SetSourceHashes(map[string]string{"/src/app/other.go": "0000"})
+	if output := tracerr.SprintSource(err); strings.Contains(output, warning) {
+		t.Errorf("tracerr.SprintSource(err) = %#v; want no warning", output)
+	}
+	tracerr.SetSourceHashes(map[string]string{"main.go": "0000"})
+	if output := tracerr.SprintSource(err); !strings.Contains(output, warning) {
+		t.Errorf("tracerr.SprintSource(err) = %#v; want warning", output)
+	}
+}
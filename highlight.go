@@ -0,0 +1,53 @@
+package tracerr
+
+import (
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// highlight colors keywords, strings and comments of a single line of Go source.
+// Line is tokenized on its own, so comments and raw strings,
+// which span multiple lines, are highlighted only partially.
+func highlight(line string) string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(line))
+	var s scanner.Scanner
+	s.Init(file, []byte(line), func(token.Position, string) {}, scanner.ScanComments)
+
+	var b strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		colorize := tokenColor(tok)
+		if colorize == nil {
+			continue
+		}
+		start := file.Offset(pos)
+		end := start + len(lit)
+		if start < last || end > len(line) {
+			continue
+		}
+		b.WriteString(line[last:start])
+		b.WriteString(colorize(line[start:end]))
+		last = end
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// tokenColor returns color function for a token, or nil if it's not colored.
+func tokenColor(tok token.Token) func(string) string {
+	switch {
+	case tok.IsKeyword():
+		return keywordColor
+	case tok == token.STRING || tok == token.CHAR:
+		return stringColor
+	case tok == token.COMMENT:
+		return commentColor
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package tracerr_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestSyntaxHighlight(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	source := strings.Join([]string{
+		"package main",
+		"",
+		"func main() { // entry point",
+		"\tpanic(\"boom\")",
+		"\treturn 'x'",
+		"}",
+	}, "\n")
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/main.go":  {Data: []byte(source)},
+		"src/main.txt": {Data: []byte(source)},
+	})
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{Func: "main.main", Line: 4, Path: "/src/main.go"},
+			{Func: "main.main", Line: 4, Path: "/src/main.txt"},
+		},
+	)
+
+	output := tracerr.SprintWithOptions(
+		err,
+		tracerr.WithSource(1, 1),
+		tracerr.WithColor(true),
+		tracerr.WithSyntaxHighlight(true),
+	)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		bold("/src/main.go:4 main.main()"),
+		black("3") + "\t" + magenta("func") + " main() { " + cyan("// entry point"),
+		red("4\t\tpanic(\"boom\")"),
+		black("5") + "\t\t" + magenta("return") + " " + green("'x'"),
+		"",
+		bold("/src/main.txt:4 main.main()"),
+		black("3") + "\tfunc main() { // entry point",
+		red("4\t\tpanic(\"boom\")"),
+		black("5") + "\t\treturn 'x'",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	// Highlighting is applied only to colorized output.
+	output = tracerr.SprintWithOptions(
+		err,
+		tracerr.WithSource(1, 1),
+		tracerr.WithSyntaxHighlight(true),
+	)
+	if output != tracerr.SprintSource(err, 1, 1) {
+		t.Errorf("output = %#v; want %#v", output, tracerr.SprintSource(err, 1, 1))
+	}
+}
+
+func green(in string) string {
+	return fmt.Sprintf("\x1b[32m%s\x1b[0m", in)
+}
+
+func magenta(in string) string {
+	return fmt.Sprintf("\x1b[35m%s\x1b[0m", in)
+}
+
+func cyan(in string) string {
+	return fmt.Sprintf("\x1b[36m%s\x1b[0m", in)
+}
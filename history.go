@@ -0,0 +1,82 @@
+package tracerr
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var historyMutex sync.Mutex
+
+// historyEnabled allows to skip locking while history is disabled.
+var historyEnabled atomic.Bool
+
+// history is a ring buffer of recently created errors,
+// next is an index of the oldest error once the buffer is full.
+var history struct {
+	errs []Error
+	next int
+	full bool
+}
+
+// SetErrorHistorySize sets how many recently created errors are kept in memory,
+// so they could be retrieved by RecentErrors.
+// Errors are recorded on creation the same way as hooks registered by OnCreate are called,
+// the oldest error is dropped once history is full.
+// History is disabled by default, pass 0 to disable it again.
+//
+// Recent errors are kept, as much as new size allows.
+func SetErrorHistorySize(n int) {
+	if n < 0 {
+		n = 0
+	}
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+	recent := recentErrors()
+	if len(recent) > n {
+		recent = recent[len(recent)-n:]
+	}
+	history.errs = make([]Error, n)
+	copy(history.errs, recent)
+	history.next = len(recent) % max(n, 1)
+	history.full = len(recent) == n && n > 0
+	historyEnabled.Store(n > 0)
+}
+
+// RecentErrors returns recently created errors, from the oldest to the newest.
+// It returns nil while history is disabled.
+func RecentErrors() []Error {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+	return recentErrors()
+}
+
+// recentErrors returns a copy of history, historyMutex must be held.
+func recentErrors() []Error {
+	if !history.full {
+		if history.next == 0 {
+			return nil
+		}
+		return append([]Error(nil), history.errs[:history.next]...)
+	}
+	recent := make([]Error, 0, len(history.errs))
+	recent = append(recent, history.errs[history.next:]...)
+	return append(recent, history.errs[:history.next]...)
+}
+
+// record adds e to history, if it's enabled.
+func record(e Error) {
+	if !historyEnabled.Load() {
+		return
+	}
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+	if len(history.errs) == 0 {
+		return
+	}
+	history.errs[history.next] = e
+	history.next++
+	if history.next == len(history.errs) {
+		history.next = 0
+		history.full = true
+	}
+}
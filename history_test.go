@@ -0,0 +1,98 @@
+package tracerr_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func messages(errs []tracerr.Error) []string {
+	var result []string
+	for _, err := range errs {
+		result = append(result, err.Error())
+	}
+	return result
+}
+
+func TestRecentErrors(t *testing.T) {
+	defer tracerr.SetErrorHistorySize(0)
+	tracerr.New("disabled")
+	if errs := tracerr.RecentErrors(); errs != nil {
+		t.Errorf("tracerr.RecentErrors() = %#v; want nil", messages(errs))
+	}
+
+	tracerr.SetErrorHistorySize(3)
+	tracerr.New("error 1")
+	tracerr.Errorf("error %d", 2)
+	expected := []string{"error 1", "error 2"}
+	if got := messages(tracerr.RecentErrors()); fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("tracerr.RecentErrors() = %#v; want %#v", got, expected)
+	}
+
+	tracerr.Wrap(fmt.Errorf("error 3"))
+	tracerr.New("error 4")
+	tracerr.New("error 5")
+	expected = []string{"error 3", "error 4", "error 5"}
+	if got := messages(tracerr.RecentErrors()); fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("tracerr.RecentErrors() = %#v; want %#v", got, expected)
+	}
+
+	tracerr.SetErrorHistorySize(2)
+	expected = []string{"error 4", "error 5"}
+	if got := messages(tracerr.RecentErrors()); fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("tracerr.RecentErrors() = %#v; want %#v", got, expected)
+	}
+
+	tracerr.SetErrorHistorySize(4)
+	tracerr.New("error 6")
+	expected = []string{"error 4", "error 5", "error 6"}
+	if got := messages(tracerr.RecentErrors()); fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("tracerr.RecentErrors() = %#v; want %#v", got, expected)
+	}
+
+	tracerr.SetErrorHistorySize(0)
+	tracerr.New("error 7")
+	if errs := tracerr.RecentErrors(); errs != nil {
+		t.Errorf("tracerr.RecentErrors() = %#v; want nil", messages(errs))
+	}
+}
+
+func TestRecentErrorsConcurrent(t *testing.T) {
+	defer tracerr.SetErrorHistorySize(0)
+	tracerr.SetErrorHistorySize(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tracerr.Errorf("error %d", j)
+				tracerr.RecentErrors()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			tracerr.SetErrorHistorySize(5 + j%10)
+		}
+	}()
+	wg.Wait()
+
+	tracerr.SetErrorHistorySize(10)
+	for i := 0; i < 10; i++ {
+		tracerr.Errorf("last %d", i)
+	}
+	errs := tracerr.RecentErrors()
+	if len(errs) != 10 {
+		t.Fatalf("len(tracerr.RecentErrors()) = %#v; want %#v", len(errs), 10)
+	}
+	for i, err := range errs {
+		if expected := fmt.Sprintf("last %d", i); err.Error() != expected {
+			t.Errorf("tracerr.RecentErrors()[%#v] = %#v; want %#v", i, err.Error(), expected)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package tracerr
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var hooksMutex sync.RWMutex
+
+var createHooks []func(Error)
+
+// OnCreate registers a hook, which is called on every error created
+// by New, Errorf, Wrap, Wrapf and RecoverError.
+// Hooks are called synchronously in order of registration,
+// after stack trace is captured.
+// Nil hook is ignored.
+func OnCreate(hook func(Error)) {
+	if hook == nil {
+		return
+	}
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	createHooks = append(createHooks, hook)
+}
+
+// rateLimit limits number of created errors passed to hooks and history.
+var rateLimit struct {
+	mutex sync.Mutex
+	n     int
+	per   time.Duration
+	// start is a start of current interval and count is a number of errors passed in it.
+	start time.Time
+	count int
+}
+
+// rateLimitEnabled allows to skip locking while there is no rate limit.
+var rateLimitEnabled atomic.Bool
+
+// rateLimitClock returns current time for rate limit intervals,
+// it's a variable to be replaced in tests.
+var rateLimitClock = time.Now
+
+// rateLimited is a number of errors not passed to hooks and history due to rate limit.
+var rateLimited atomic.Uint64
+
+// SetCreateRateLimit limits number of created errors passed to hooks
+// registered by OnCreate and to history, see SetErrorHistorySize,
+// to n errors per interval, such as to not flood external system in a tight retry loop.
+// Excess errors are still returned to callers, and they are counted by RateLimitedErrors.
+// Pass n <= 0 to remove the limit, which is a default.
+// Interval per must be positive, otherwise the limit is removed as well.
+func SetCreateRateLimit(n int, per time.Duration) {
+	rateLimit.mutex.Lock()
+	defer rateLimit.mutex.Unlock()
+	rateLimit.n = n
+	rateLimit.per = per
+	rateLimit.start = time.Time{}
+	rateLimit.count = 0
+	rateLimitEnabled.Store(n > 0 && per > 0)
+}
+
+// RateLimitedErrors returns a number of created errors,
+// which were not passed to hooks and history due to SetCreateRateLimit.
+func RateLimitedErrors() uint64 {
+	return rateLimited.Load()
+}
+
+// allowed reports whether created error is within rate limit.
+func allowed() bool {
+	if !rateLimitEnabled.Load() {
+		return true
+	}
+	rateLimit.mutex.Lock()
+	defer rateLimit.mutex.Unlock()
+	if rateLimit.n <= 0 || rateLimit.per <= 0 {
+		return true
+	}
+	now := rateLimitClock()
+	if now.Sub(rateLimit.start) >= rateLimit.per {
+		rateLimit.start = now
+		rateLimit.count = 0
+	}
+	if rateLimit.count >= rateLimit.n {
+		rateLimited.Add(1)
+		return false
+	}
+	rateLimit.count++
+	return true
+}
+
+// created records e to history, calls registered hooks with e and returns e.
+// Errors over the limit set by SetCreateRateLimit are returned as is.
+func created(e Error) Error {
+	if !allowed() {
+		return e
+	}
+	record(e)
+	hooksMutex.RLock()
+	hooks := createHooks
+	hooksMutex.RUnlock()
+	for _, hook := range hooks {
+		hook(e)
+	}
+	return e
+}
+
+// Shutdown flushes pending reports of asynchronous sinks and stops their goroutines,
+// blocking until it's done or ctx is done, so it should be called on program exit.
+// It returns ctx error if ctx is done before.
+//
+// Hooks registered by OnCreate and history are synchronous, and there are no asynchronous sinks yet,
+// so for now Shutdown does nothing and returns nil, even if ctx is done.
+func Shutdown(ctx context.Context) error {
+	return nil
+}
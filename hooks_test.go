@@ -0,0 +1,213 @@
+package tracerr_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestOnCreate(t *testing.T) {
+	defer tracerr.ResetCreateHooks()
+	var created []tracerr.Error
+	tracerr.OnCreate(nil)
+	tracerr.OnCreate(func(e tracerr.Error) {
+		if len(e.StackTrace()) == 0 {
+			t.Errorf("hook got %#v with empty stack trace", e.Error())
+		}
+		created = append(created, e)
+	})
+	var calls int
+	tracerr.OnCreate(func(e tracerr.Error) {
+		calls++
+	})
+
+	errs := []tracerr.Error{
+		tracerr.New("new error"),
+		tracerr.Errorf("errorf %d", 1),
+		tracerr.Wrap(errors.New("wrap error")),
+		tracerr.Wrapf(errors.New("wrapf error"), "context"),
+		tracerr.RecoverError("recovered").(tracerr.Error),
+	}
+	if len(created) != len(errs) {
+		t.Fatalf("len(created) = %#v; want %#v", len(created), len(errs))
+	}
+	for i, err := range errs {
+		if created[i] != err {
+			t.Errorf("created[%#v] = %#v; want %#v", i, created[i].Error(), err.Error())
+		}
+	}
+	if calls != len(errs) {
+		t.Errorf("calls = %#v; want %#v", calls, len(errs))
+	}
+
+	tracerr.Wrap(errs[0])
+	tracerr.CustomError(errors.New("custom error"), nil)
+	if len(created) != len(errs) {
+		t.Errorf("len(created) = %#v; want %#v", len(created), len(errs))
+	}
+}
+
+func TestOnCreateConcurrent(t *testing.T) {
+	defer tracerr.ResetCreateHooks()
+	var mu sync.Mutex
+	var calls int
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracerr.OnCreate(func(tracerr.Error) {
+				mu.Lock()
+				calls++
+				mu.Unlock()
+			})
+			tracerr.New("concurrent error")
+		}()
+	}
+	wg.Wait()
+	if calls == 0 {
+		t.Errorf("calls = %#v; want > 0", calls)
+	}
+}
+
+func TestSetCreateRateLimit(t *testing.T) {
+	defer tracerr.ResetCreateHooks()
+	defer tracerr.SetCreateRateLimit(0, 0)
+	var mutex sync.Mutex
+	calls := 0
+	tracerr.OnCreate(func(e tracerr.Error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls++
+	})
+
+	tracerr.SetCreateRateLimit(5, time.Hour)
+	limited := tracerr.RateLimitedErrors()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := tracerr.New("some error"); err == nil {
+					t.Errorf("tracerr.New(...) = nil; want error")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 5 {
+		t.Errorf("calls = %#v; want %#v", calls, 5)
+	}
+	if n := tracerr.RateLimitedErrors() - limited; n != 95 {
+		t.Errorf("tracerr.RateLimitedErrors() = %#v; want %#v more", n, 95)
+	}
+
+	// Limit is reset for a new interval.
+	now := time.Now()
+	defer tracerr.SetRateLimitClock(nil)
+	tracerr.SetRateLimitClock(func() time.Time {
+		return now
+	})
+	tracerr.SetCreateRateLimit(2, time.Minute)
+	tracerr.New("error 1")
+	tracerr.New("error 2")
+	tracerr.New("error 3")
+	now = now.Add(time.Minute)
+	tracerr.New("error 4")
+	if calls != 8 {
+		t.Errorf("calls = %#v; want %#v", calls, 8)
+	}
+
+	tracerr.SetCreateRateLimit(0, 0)
+	for i := 0; i < 10; i++ {
+		tracerr.New("unlimited")
+	}
+	if calls != 18 {
+		t.Errorf("calls = %#v; want %#v", calls, 18)
+	}
+
+	// Limit with no interval is removed.
+	limited = tracerr.RateLimitedErrors()
+	tracerr.SetCreateRateLimit(1, 0)
+	for i := 0; i < 10; i++ {
+		tracerr.New("no interval")
+	}
+	if calls != 28 {
+		t.Errorf("calls = %#v; want %#v", calls, 28)
+	}
+	if n := tracerr.RateLimitedErrors() - limited; n != 0 {
+		t.Errorf("tracerr.RateLimitedErrors() = %#v; want %#v more", n, 0)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tracerr.Shutdown(ctx); err != nil {
+		t.Errorf("tracerr.Shutdown(ctx) = %#v; want nil", err)
+	}
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	// There is nothing to flush, so there is nothing to wait for.
+	if err := tracerr.Shutdown(expired); err != nil {
+		t.Errorf("tracerr.Shutdown(expired) = %#v; want nil", err)
+	}
+}
+
+func TestOnCreateJoin(t *testing.T) {
+	defer tracerr.ResetCreateHooks()
+	var created []string
+	tracerr.OnCreate(func(e tracerr.Error) {
+		created = append(created, e.Error())
+	})
+	traced := tracerr.New("traced error")
+	created = nil
+	err := tracerr.Join(errors.New("first error"), traced, errors.New("second error"))
+	expected := []string{"first error", "second error"}
+	if len(created) != len(expected) || created[0] != expected[0] || created[1] != expected[1] {
+		t.Errorf("created = %#v; want %#v", created, expected)
+	}
+	if !errors.Is(err, traced) {
+		t.Errorf("errors.Is(err, traced) = false; want true")
+	}
+}
+
+func TestOnCreateWithValueAndKind(t *testing.T) {
+	defer tracerr.ResetCreateHooks()
+	var created []tracerr.Error
+	tracerr.OnCreate(func(e tracerr.Error) {
+		created = append(created, e)
+	})
+	plain := errors.New("plain error")
+
+	err := tracerr.WithValue(plain, "request_id", "42")
+	if len(created) != 1 || created[0] != err {
+		t.Fatalf("created = %#v; want %#v", created, []tracerr.Error{err})
+	}
+	if values := tracerr.Values(created[0]); values["request_id"] != "42" {
+		t.Errorf("tracerr.Values(created[0]) = %#v; want request_id", values)
+	}
+	if frame := err.StackTrace()[0]; frame.Func != "github.com/ztrue/tracerr_test.TestOnCreateWithValueAndKind" {
+		t.Errorf("err.StackTrace()[0].Func = %#v; want test function", frame.Func)
+	}
+
+	err = tracerr.WithKind(plain, "not_found")
+	if len(created) != 2 || created[1] != err {
+		t.Fatalf("created = %#v; want %#v as the last one", created, err)
+	}
+	if kind := tracerr.KindOf(created[1]); kind != "not_found" {
+		t.Errorf("tracerr.KindOf(created[1]) = %#v; want %#v", kind, "not_found")
+	}
+
+	// Errors with stack trace are not created again.
+	tracerr.WithKind(tracerr.WithValue(err, "user", "john"), "internal")
+	if len(created) != 2 {
+		t.Errorf("len(created) = %#v; want %#v", len(created), 2)
+	}
+}
@@ -0,0 +1,77 @@
+package tracerr
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// SprintHTML returns error output as HTML fragment:
+// error message in a heading, each frame in a collapsible block
+// and source fragment in a pre block with traced line highlighted.
+// Number of source lines is defined by the same rules as in PrintSource.
+//
+// Elements have classes with "tracerr" prefix for styling:
+//
+//	<div class="tracerr">
+//	<h3 class="tracerr-message">some error</h3>
+//	<details class="tracerr-frame" open>
+//	<summary>/src/main.go:42 main.foo()</summary>
+//	<pre class="tracerr-source"><span class="tracerr-line">41</span>	...
+//	<span class="tracerr-traced">42	...</span>
+//	</pre>
+//	</details>
+//	</div>
+//
+// All messages, paths and source lines are HTML-escaped.
+func SprintHTML(err error, nums ...int) string {
+	if err == nil {
+		return ""
+	}
+	rows := []string{
+		`<div class="tracerr">`,
+		fmt.Sprintf(`<h3 class="tracerr-message">%s</h3>`, html.EscapeString(err.Error())),
+	}
+	if e, ok := err.(Error); ok {
+		o := newOptions([]Option{WithSource(nums...)})
+		before, after, withSource := calcRows(o.nums)
+		for _, frame := range o.ordered(o.selected(e.StackTrace())) {
+			rows = append(rows,
+				`<details class="tracerr-frame" open>`,
+				fmt.Sprintf("<summary>%s</summary>", html.EscapeString(frame.String())),
+			)
+			if withSource {
+				if source := htmlSource(frame.Frame, before, after); source != "" {
+					rows = append(rows, source)
+				}
+			}
+			rows = append(rows, "</details>")
+		}
+	}
+	rows = append(rows, "</div>")
+	return strings.Join(rows, "\n")
+}
+
+func htmlSource(frame Frame, before, after int) string {
+	window, err := sourceWindow(frame, before, after)
+	if err != nil {
+		message := sourceErrorMessage(frame, err)
+		if message == "" {
+			return ""
+		}
+		return fmt.Sprintf(`<p class="tracerr-warning">%s</p>`, html.EscapeString(message))
+	}
+	var b strings.Builder
+	b.WriteString(`<pre class="tracerr-source">`)
+	for _, line := range window {
+		text := html.EscapeString(line.Text)
+		if line.Traced {
+			fmt.Fprintf(&b, `<span class="tracerr-traced">%d	%s</span>`, line.Number, text)
+		} else {
+			fmt.Fprintf(&b, `<span class="tracerr-line">%d</span>	%s`, line.Number, text)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("</pre>")
+	return b.String()
+}
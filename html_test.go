@@ -0,0 +1,67 @@
+package tracerr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestSprintHTML(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/main.go": {
+			Data: []byte("package main\n\nfunc main() {\n\tif a < b && c > d {\n\t}\n}\n"),
+		},
+	})
+	err := tracerr.CustomError(
+		errors.New("<script>alert(1)</script>"),
+		[]tracerr.Frame{
+			{
+				Func: "main.main",
+				Line: 4,
+				Path: "/src/main.go",
+			},
+			{
+				Func: "main.<foo>",
+				Line: 1,
+				Path: "/src/foo.go",
+			},
+		},
+	)
+	output := tracerr.SprintHTML(err, 1, 1)
+	expected := strings.Join([]string{
+		`<div class="tracerr">`,
+		`<h3 class="tracerr-message">&lt;script&gt;alert(1)&lt;/script&gt;</h3>`,
+		`<details class="tracerr-frame" open>`,
+		`<summary>/src/main.go:4 main.main()</summary>`,
+		`<pre class="tracerr-source"><span class="tracerr-line">3</span>	func main() {`,
+		`<span class="tracerr-traced">4		if a &lt; b &amp;&amp; c &gt; d {</span>`,
+		`<span class="tracerr-line">5</span>		}`,
+		`</pre>`,
+		`</details>`,
+		`<details class="tracerr-frame" open>`,
+		`<summary>/src/foo.go:1 main.&lt;foo&gt;()</summary>`,
+		`<p class="tracerr-warning">tracerr: file /src/foo.go not found</p>`,
+		`</details>`,
+		`</div>`,
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintHTML(err, 1, 1) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintHTML(err, 0)
+	if strings.Contains(output, "<pre") || strings.Contains(output, "tracerr-warning") {
+		t.Errorf("tracerr.SprintHTML(err, 0) = %#v; want no source", output)
+	}
+
+	if output := tracerr.SprintHTML(nil); output != "" {
+		t.Errorf("tracerr.SprintHTML(nil) = %#v; want %#v", output, "")
+	}
+	expected = "<div class=\"tracerr\">\n<h3 class=\"tracerr-message\">a &amp; b</h3>\n</div>"
+	if output := tracerr.SprintHTML(errors.New("a & b")); output != expected {
+		t.Errorf("tracerr.SprintHTML(err) = %#v; want %#v", output, expected)
+	}
+}
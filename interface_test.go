@@ -0,0 +1,74 @@
+package tracerr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+// externalError implements tracerr.Error outside of the package.
+type externalError struct {
+	frames []tracerr.Frame
+}
+
+func (e externalError) Error() string               { return "external error" }
+func (e externalError) StackTrace() []tracerr.Frame { return e.frames }
+func (e externalError) Unwrap() error               { return nil }
+
+func TestExternalError(t *testing.T) {
+	var err tracerr.Error = externalError{
+		frames: []tracerr.Frame{{Func: "main.foo", Line: 42, Path: "/src/main.go"}},
+	}
+	expected := "external error\n/src/main.go:42 main.foo()"
+	if output := tracerr.Sprint(err); output != expected {
+		t.Errorf("tracerr.Sprint(err) = %#v; want %#v", output, expected)
+	}
+	if id := tracerr.GoroutineID(err); id != 0 {
+		t.Errorf("tracerr.GoroutineID(err) = %#v; want 0", id)
+	}
+	if ts := tracerr.Timestamp(err); !ts.IsZero() {
+		t.Errorf("tracerr.Timestamp(err) = %v; want zero", ts)
+	}
+	if values := tracerr.Values(err); values != nil {
+		t.Errorf("tracerr.Values(err) = %#v; want nil", values)
+	}
+	if kind := tracerr.KindOf(err); kind != "" {
+		t.Errorf("tracerr.KindOf(err) = %#v; want empty", kind)
+	}
+	if value := tracerr.PanicValue(err); value != nil {
+		t.Errorf("tracerr.PanicValue(err) = %#v; want nil", value)
+	}
+
+	clone := tracerr.Clone(err)
+	if clone.Error() != err.Error() || len(clone.StackTrace()) != 1 {
+		t.Errorf("tracerr.Clone(err) = %#v; want a copy of err", clone)
+	}
+	clone.StackTrace()[0].Line = 1
+	if err.StackTrace()[0].Line != 42 {
+		t.Errorf("err.StackTrace()[0].Line = %#v; want unchanged %#v", err.StackTrace()[0].Line, 42)
+	}
+}
+
+func TestAccessorsInChain(t *testing.T) {
+	err := fmt.Errorf("context: %w", tracerr.WithValue(tracerr.NewWithKind(tracerr.KindNotFound, "some error"), "user", "john"))
+	if kind := tracerr.KindOf(err); kind != tracerr.KindNotFound {
+		t.Errorf("tracerr.KindOf(err) = %#v; want %#v", kind, tracerr.KindNotFound)
+	}
+	if values := tracerr.Values(err); values["user"] != "john" {
+		t.Errorf("tracerr.Values(err) = %#v; want user", values)
+	}
+	if ts := tracerr.Timestamp(err); ts.IsZero() {
+		t.Errorf("tracerr.Timestamp(err) is zero; want creation time")
+	}
+	if message := tracerr.Message(err); message != "context: some error" {
+		t.Errorf("tracerr.Message(err) = %#v; want %#v", message, "context: some error")
+	}
+	if stack := tracerr.StackString(err); stack != tracerr.StackString(errors.Unwrap(err)) || stack == "" {
+		t.Errorf("tracerr.StackString(err) = %#v; want frames of traced error", stack)
+	}
+	if message := tracerr.Message(nil); message != "" {
+		t.Errorf("tracerr.Message(nil) = %#v; want empty", message)
+	}
+}
@@ -0,0 +1,112 @@
+package tracerr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// joinError is an error that aggregates multiple errors,
+// each of them could have its own stack trace.
+type joinError struct {
+	errs []error
+}
+
+// Join returns an error that wraps all non-nil errs.
+// Each of errs is wrapped the same way as by Wrap, so it keeps its own stack trace,
+// errors without stack trace get one added, and OnCreate hooks are called for them.
+// Nested joins and other errors, which unwrap to several errors, are kept as is.
+//
+// Output contains each error with its own frames under a shared header.
+// The joined error implements Unwrap() []error,
+// so errors.Is and errors.As check all of errs.
+// For that reason it's not of type Error, which unwraps to a single error,
+// and has no stack trace of its own.
+//
+// Join returns nil if all errs are nil,
+// and the same as Wrap if there is only one non-nil error.
+func Join(errs ...error) error {
+	joined := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		joined = append(joined, wrapMember(err))
+	}
+	switch len(joined) {
+	case 0:
+		return nil
+	case 1:
+		return joined[0]
+	}
+	return &joinError{errs: joined}
+}
+
+// wrapMember wraps err the same way as Wrap does,
+// but errors which unwrap to several errors, such as nested Join results, are kept as is,
+// so each of them keeps its own stack trace.
+func wrapMember(err error) error {
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		return err
+	}
+	// Skip wrapMember.
+	return wrap(err, 1)
+}
+
+// Error returns messages of all errors separated by newline,
+// the same way as errors.Join does.
+func (e *joinError) Error() string {
+	messages := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns joined errors.
+func (e *joinError) Unwrap() []error {
+	return e.errs
+}
+
+func sprintJoin(e *joinError, o *options) string {
+	entries := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		entries = append(entries, sprint(err, o))
+	}
+	return sprintEntries(fmt.Sprintf("%d errors occurred:", len(e.errs)), entries, o)
+}
+
+// sprintEntries returns header followed by entries, such as joined errors,
+// either one after another or as branches of a tree if WithTree is set.
+func sprintEntries(header string, entries []string, o *options) string {
+	rows := make([]string, 0, len(entries)*2+1)
+	rows = append(rows, header)
+	for i, entry := range entries {
+		if !o.compactSpacing {
+			if o.tree {
+				rows = append(rows, "│")
+			} else {
+				rows = append(rows, "")
+			}
+		}
+		if !o.tree {
+			rows = append(rows, entry)
+			continue
+		}
+		branch, indent := "├─ ", "│  "
+		if i == len(entries)-1 {
+			branch, indent = "└─ ", "   "
+		}
+		for j, row := range strings.Split(entry, "\n") {
+			switch {
+			case j == 0:
+				row = branch + row
+			case row == "":
+				row = strings.TrimRight(indent, " ")
+			default:
+				row = indent + row
+			}
+			rows = append(rows, row)
+		}
+	}
+	return strings.Join(rows, "\n")
+}
@@ -0,0 +1,102 @@
+package tracerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestJoin(t *testing.T) {
+	first := errors.New("first error")
+	codeErr := &codeError{Code: 500}
+	err := tracerr.Join(nil, first, tracerr.Wrap(codeErr), nil)
+
+	if err.Error() != "first error\ncode 500" {
+		t.Errorf(
+			"err.Error() = %#v; want %#v",
+			err.Error(), "first error\ncode 500",
+		)
+	}
+	if !errors.Is(err, first) {
+		t.Errorf("errors.Is(err, first) = false; want true")
+	}
+	var target *codeError
+	if !errors.As(err, &target) || target != codeErr {
+		t.Errorf("errors.As(err, &target): target = %#v; want %#v", target, codeErr)
+	}
+	joined := err.(interface{ Unwrap() []error }).Unwrap()
+	if len(joined) != 2 {
+		t.Fatalf("len(err.Unwrap()) = %#v; want %#v", len(joined), 2)
+	}
+	for i, e := range joined {
+		if len(tracerr.StackTrace(e)) == 0 {
+			t.Errorf("tracerr.StackTrace(err.Unwrap()[%#v]) is empty", i)
+		}
+	}
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithMaxFrames(1))
+	expectedRows := []string{
+		"2 errors occurred:",
+		"",
+		"first error",
+		"/tracerr/join_test.go:13 github.com/ztrue/tracerr_test.TestJoin()",
+		"",
+		"code 500",
+		"/tracerr/join_test.go:13 github.com/ztrue/tracerr_test.TestJoin()",
+	}
+	assertRows(t, 0, output, expectedRows, 0)
+}
+
+func TestJoinSingle(t *testing.T) {
+	if err := tracerr.Join(); err != nil {
+		t.Errorf("tracerr.Join() = %#v; want nil", err)
+	}
+	if err := tracerr.Join(nil, nil); err != nil {
+		t.Errorf("tracerr.Join(nil, nil) = %#v; want nil", err)
+	}
+
+	traced := tracerr.New("traced error")
+	if err := tracerr.Join(nil, traced); err != traced {
+		t.Errorf("tracerr.Join(nil, traced) = %#v; want %#v", err, traced)
+	}
+
+	regular := errors.New("regular error")
+	err := tracerr.Join(regular, nil)
+	e, ok := err.(tracerr.Error)
+	if !ok {
+		t.Fatalf("tracerr.Join(regular, nil) = %#v; want tracerr.Error", err)
+	}
+	if e.Unwrap() != regular {
+		t.Errorf("tracerr.Join(regular, nil).Unwrap() = %#v; want %#v", e.Unwrap(), regular)
+	}
+	frame := e.StackTrace()[0]
+	if frame.Func != "github.com/ztrue/tracerr_test.TestJoinSingle" || frame.Line != 65 {
+		t.Errorf("tracerr.Join(regular, nil).StackTrace()[0] = %#v; want TestJoinSingle at line 65", frame)
+	}
+}
+
+func TestWrapJoin(t *testing.T) {
+	first := tracerr.CustomError(errors.New("first error"), []tracerr.Frame{
+		{Func: "main.foo", Line: 10, Path: "/src/foo.go"},
+	})
+	second := errors.New("second error")
+	joined := tracerr.Join(first, second)
+
+	for _, err := range []tracerr.Error{tracerr.Wrap(joined), tracerr.Wrapf(joined, "wrapped")} {
+		frames := err.StackTrace()
+		if len(frames) == 0 || frames[0].Func != "github.com/ztrue/tracerr_test.TestWrapJoin" {
+			t.Errorf("err.StackTrace() = %#v; want new stack trace", frames)
+		}
+		if !errors.Is(err, first) || !errors.Is(err, second) {
+			t.Errorf("errors.Is(err, ...) = false; want true for all joined errors")
+		}
+	}
+
+	// Nested join keeps stack traces of its errors.
+	nested := tracerr.Join(joined, errors.New("third error"))
+	members := nested.(interface{ Unwrap() []error }).Unwrap()
+	if members[0] != joined {
+		t.Errorf("members[0] = %#v; want %#v", members[0], joined)
+	}
+}
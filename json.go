@@ -0,0 +1,135 @@
+package tracerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type jsonError struct {
+	Error  string      `json:"error"`
+	Time   string      `json:"time,omitempty"`
+	Frames []jsonFrame `json:"frames,omitempty"`
+}
+
+type jsonFrame struct {
+	Func    string         `json:"func"`
+	File    string         `json:"file"`
+	Line    int            `json:"line"`
+	EndLine int            `json:"end_line,omitempty"`
+	Col     int            `json:"col,omitempty"`
+	Source  map[int]string `json:"source,omitempty"`
+}
+
+// SprintJSON returns error message with stack trace as JSON object:
+//
+//	{"error":"some error","frames":[{"func":"main.foo","file":"/src/main.go","line":42}]}
+//
+// Time of error creation is added in RFC 3339 format, if it's recorded.
+// Frames are omitted if err is not of type Error.
+// Displayed frames are defined by DefaultMaxFrames, DefaultIgnoreFirstFrames
+// and DefaultIgnoreLastFrames, the same way as in Sprint.
+func SprintJSON(err error) (string, error) {
+	return sprintJSON(err, []int{0})
+}
+
+// SprintSourceJSON returns the same JSON object as SprintJSON,
+// but each frame also contains source lines keyed by line number:
+//
+//	{"func":"main.foo","file":"/src/main.go","line":42,"source":{"41":"...","42":"..."}}
+//
+// Number of source lines is defined by the same rules as in PrintSource.
+func SprintSourceJSON(err error, nums ...int) (string, error) {
+	return sprintJSON(err, nums)
+}
+
+func sprintJSON(err error, nums []int) (string, error) {
+	if err == nil {
+		return "", nil
+	}
+	data := jsonError{
+		Error: err.Error(),
+	}
+	if e, ok := err.(Error); ok {
+		if ts := Timestamp(e); !ts.IsZero() {
+			data.Time = ts.Format(time.RFC3339Nano)
+		}
+		before, after, withSource := calcRows(nums)
+		frames := newOptions(nil).frames(e.StackTrace())
+		data.Frames = make([]jsonFrame, 0, len(frames))
+		for _, frame := range frames {
+			f := jsonFrame{
+				Func:    frame.Func,
+				File:    frame.Path,
+				Line:    frame.Line,
+				EndLine: frame.EndLine,
+				Col:     frame.Col,
+			}
+			if withSource {
+				f.Source = sourceLines(frame, before, after)
+			}
+			data.Frames = append(data.Frames, f)
+		}
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// ParseJSON creates an error from JSON object produced by SprintJSON
+// or SprintSourceJSON, such as a trace received from another service.
+//
+// Source lines from JSON are ignored,
+// so printing the error reads local source files, if they exist.
+func ParseJSON(data []byte) (Error, error) {
+	var parsed struct {
+		Error  *string     `json:"error"`
+		Time   string      `json:"time"`
+		Frames []jsonFrame `json:"frames"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("tracerr: parse JSON trace: %w", err)
+	}
+	if parsed.Error == nil {
+		return nil, errors.New(`tracerr: parse JSON trace: missing "error" field`)
+	}
+	frames := make([]Frame, 0, len(parsed.Frames))
+	for _, f := range parsed.Frames {
+		frames = append(frames, Frame{
+			Func:    f.Func,
+			Line:    f.Line,
+			Path:    f.File,
+			EndLine: f.EndLine,
+			Col:     f.Col,
+		})
+	}
+	e := &errorData{
+		err:    errors.New(*parsed.Error),
+		frames: frames,
+	}
+	if parsed.Time != "" {
+		t, err := time.Parse(time.RFC3339Nano, parsed.Time)
+		if err != nil {
+			return nil, fmt.Errorf("tracerr: parse JSON trace: invalid time: %w", err)
+		}
+		e.timestamp = t
+	}
+	return e, nil
+}
+
+// sourceLines returns source lines around traced line keyed by line number.
+// It returns nil if source is not available.
+func sourceLines(frame Frame, before, after int) map[int]string {
+	window, err := sourceWindow(frame, before, after)
+	if err != nil {
+		return nil
+	}
+	source := make(map[int]string, len(window))
+	for _, line := range window {
+		source[line.Number] = line.Text
+	}
+	return source
+}
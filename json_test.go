@@ -0,0 +1,201 @@
+package tracerr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+type jsonTestError struct {
+	Error  string `json:"error"`
+	Frames []struct {
+		Func   string            `json:"func"`
+		File   string            `json:"file"`
+		Line   int               `json:"line"`
+		Source map[string]string `json:"source"`
+	} `json:"frames"`
+}
+
+func TestSprintJSON(t *testing.T) {
+	err := addFrameA("json error")
+	output, jsonErr := tracerr.SprintJSON(err)
+	if jsonErr != nil {
+		t.Fatalf("tracerr.SprintJSON(err) error = %#v; want nil", jsonErr)
+	}
+	var data jsonTestError
+	if jsonErr := json.Unmarshal([]byte(output), &data); jsonErr != nil {
+		t.Fatalf("json.Unmarshal(%#v) error = %#v; want nil", output, jsonErr)
+	}
+	if data.Error != "json error" {
+		t.Errorf("data.Error = %#v; want %#v", data.Error, "json error")
+	}
+	frames := err.(tracerr.Error).StackTrace()
+	if len(data.Frames) != len(frames) {
+		t.Fatalf("len(data.Frames) = %#v; want %#v", len(data.Frames), len(frames))
+	}
+	for i, frame := range frames {
+		f := data.Frames[i]
+		if f.Func != frame.Func || f.File != frame.Path || f.Line != frame.Line {
+			t.Errorf(
+				"data.Frames[%#v] = %#v; want %#v",
+				i, f, frame,
+			)
+		}
+		if f.Source != nil {
+			t.Errorf("data.Frames[%#v].Source = %#v; want nil", i, f.Source)
+		}
+	}
+}
+
+func TestSprintSourceJSON(t *testing.T) {
+	err := addFrameA("json error")
+	output, jsonErr := tracerr.SprintSourceJSON(err, 1, 1)
+	if jsonErr != nil {
+		t.Fatalf("tracerr.SprintSourceJSON(err) error = %#v; want nil", jsonErr)
+	}
+	var data jsonTestError
+	if jsonErr := json.Unmarshal([]byte(output), &data); jsonErr != nil {
+		t.Fatalf("json.Unmarshal(%#v) error = %#v; want nil", output, jsonErr)
+	}
+	if !strings.HasSuffix(data.Frames[0].File, "/tracerr/error_helper_test.go") {
+		t.Errorf(
+			"data.Frames[0].File = %#v; want to has suffix %#v",
+			data.Frames[0].File, "/tracerr/error_helper_test.go",
+		)
+	}
+	expected := map[string]string{
+		"16": "func addFrameC(message string) error {",
+		"17": "\treturn tracerr.New(message)",
+		"18": "}",
+	}
+	source := data.Frames[0].Source
+	if len(source) != len(expected) {
+		t.Errorf("data.Frames[0].Source = %#v; want %#v", source, expected)
+	}
+	for line, text := range expected {
+		if source[line] != text {
+			t.Errorf(
+				"data.Frames[0].Source[%#v] = %#v; want %#v",
+				line, source[line], text,
+			)
+		}
+	}
+}
+
+func TestSprintJSONNotInstance(t *testing.T) {
+	cases := []struct {
+		Error    error
+		Expected string
+	}{
+		{
+			Error:    nil,
+			Expected: "",
+		},
+		{
+			Error:    errors.New("regular error"),
+			Expected: `{"error":"regular error"}`,
+		},
+		{
+			Error: tracerr.CustomError(
+				errors.New("some error"),
+				[]tracerr.Frame{
+					{
+						Func: "main.Foo",
+						Line: 42,
+						Path: "/tmp/not_exists.go",
+					},
+				},
+			),
+			Expected: `{"error":"some error","frames":[{"func":"main.Foo","file":"/tmp/not_exists.go","line":42}]}`,
+		},
+	}
+
+	for i, c := range cases {
+		output, err := tracerr.SprintSourceJSON(c.Error)
+		if err != nil {
+			t.Errorf("case #%d: error = %#v; want nil", i, err)
+		}
+		if output != c.Expected {
+			t.Errorf(
+				"case #%d: tracerr.SprintSourceJSON(err) = %#v; want %#v",
+				i, output, c.Expected,
+			)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	err := addFrameA("remote error")
+	output, jsonErr := tracerr.SprintSourceJSON(err)
+	if jsonErr != nil {
+		t.Fatalf("tracerr.SprintSourceJSON(err) error = %#v; want nil", jsonErr)
+	}
+	parsed, parseErr := tracerr.ParseJSON([]byte(output))
+	if parseErr != nil {
+		t.Fatalf("tracerr.ParseJSON(%#v) error = %#v; want nil", output, parseErr)
+	}
+	if parsed.Error() != "remote error" {
+		t.Errorf("parsed.Error() = %#v; want %#v", parsed.Error(), "remote error")
+	}
+	frames := err.(tracerr.Error).StackTrace()
+	parsedFrames := parsed.StackTrace()
+	if len(parsedFrames) != len(frames) {
+		t.Fatalf("len(parsed.StackTrace()) = %#v; want %#v", len(parsedFrames), len(frames))
+	}
+	for i, frame := range frames {
+		if parsedFrames[i] != frame {
+			t.Errorf("parsed.StackTrace()[%#v] = %#v; want %#v", i, parsedFrames[i], frame)
+		}
+	}
+	if !tracerr.Timestamp(parsed).Equal(tracerr.Timestamp(err)) {
+		t.Errorf("tracerr.Timestamp(parsed) = %#v; want %#v", tracerr.Timestamp(parsed), tracerr.Timestamp(err))
+	}
+	if source, expected := tracerr.SprintSource(parsed), tracerr.SprintSource(err); source != expected {
+		t.Errorf("tracerr.SprintSource(parsed) = %#v; want %#v", source, expected)
+	}
+}
+
+func TestParseJSONMissingSource(t *testing.T) {
+	data := `{"error":"remote error","frames":[{"func":"main.foo","file":"/no/such/file.go","line":42}]}`
+	parsed, err := tracerr.ParseJSON([]byte(data))
+	if err != nil {
+		t.Fatalf("tracerr.ParseJSON(%#v) error = %#v; want nil", data, err)
+	}
+	output := tracerr.SprintSource(parsed)
+	expected := strings.Join([]string{
+		"remote error",
+		"",
+		"/no/such/file.go:42 main.foo()",
+		"tracerr: file /no/such/file.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(parsed) = %#v; want %#v", output, expected)
+	}
+}
+
+func TestParseJSONInvalid(t *testing.T) {
+	cases := []string{
+		``,
+		`{"error":`,
+		`[]`,
+		`{"frames":[]}`,
+		`{"error":"some error","time":"yesterday"}`,
+		`{"error":"some error","frames":[{"line":"42"}]}`,
+	}
+	for i, data := range cases {
+		parsed, err := tracerr.ParseJSON([]byte(data))
+		if err == nil {
+			t.Errorf("cases[%#v]: tracerr.ParseJSON(%#v) error = nil; want error", i, data)
+			continue
+		}
+		if parsed != nil {
+			t.Errorf("cases[%#v]: tracerr.ParseJSON(%#v) = %#v; want nil", i, data, parsed)
+		}
+		if !strings.HasPrefix(err.Error(), "tracerr: parse JSON trace: ") {
+			t.Errorf("cases[%#v]: err.Error() = %#v; want tracerr prefix", i, err.Error())
+		}
+	}
+}
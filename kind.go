@@ -0,0 +1,67 @@
+package tracerr
+
+import (
+	"errors"
+)
+
+// Kind is a category of an error, such as KindNotFound,
+// so errors could be told apart with no message matching.
+// Any other string is a valid kind as well.
+type Kind string
+
+const (
+	// KindValidation is a kind of errors caused by invalid input.
+	KindValidation Kind = "validation"
+	// KindNotFound is a kind of errors caused by missing entities.
+	KindNotFound Kind = "not_found"
+	// KindInternal is a kind of unexpected errors.
+	KindInternal Kind = "internal"
+)
+
+// NewWithKind creates new error with stacktrace and kind.
+func NewWithKind(kind Kind, message string) Error {
+	e := trace(errors.New(message), 2).(*errorData)
+	e.kind = kind
+	return created(e)
+}
+
+// WithKind returns a new error with the same message and stack trace, and with kind,
+// which overrides a kind set earlier. Kind is kept by Wrap and Wrapf.
+//
+// If there is no Error in err chain, stack trace is added the same way as in Wrap.
+// It returns nil if err is nil.
+func WithKind(err error, kind Kind) Error {
+	if err == nil {
+		return nil
+	}
+	return derive(err, 0, func(data *errorData) {
+		data.kind = kind
+	})
+}
+
+// Kind returns a kind of an error, set by NewWithKind or WithKind.
+// It's empty if kind is not set.
+func (e *errorData) Kind() Kind {
+	return e.kind
+}
+
+// KindOf returns a kind of the first Error in err chain, set by NewWithKind or WithKind.
+// It's empty if there is no such Error or kind is not set.
+func KindOf(err error) Kind {
+	var e interface{ Kind() Kind }
+	if !errors.As(err, &e) {
+		return ""
+	}
+	return e.Kind()
+}
+
+// HasKind reports whether there is an Error of kind in err chain.
+func HasKind(err error, kind Kind) bool {
+	for err != nil {
+		if e, ok := err.(interface{ Kind() Kind }); ok && e.Kind() == kind {
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package tracerr_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestNewWithKind(t *testing.T) {
+	err := tracerr.NewWithKind(tracerr.KindNotFound, "user not found")
+	if tracerr.KindOf(err) != tracerr.KindNotFound {
+		t.Errorf("tracerr.KindOf(err) = %#v; want %#v", tracerr.KindOf(err), tracerr.KindNotFound)
+	}
+	if err.Error() != "user not found" {
+		t.Errorf("err.Error() = %#v; want %#v", err.Error(), "user not found")
+	}
+	if frames := err.StackTrace(); len(frames) == 0 || frames[0].Func != "github.com/ztrue/tracerr_test.TestNewWithKind" {
+		t.Errorf("err.StackTrace() = %#v; want TestNewWithKind first", frames)
+	}
+	if kind := tracerr.KindOf(tracerr.New("some error")); kind != "" {
+		t.Errorf("tracerr.KindOf(tracerr.New(...)) = %#v; want empty", kind)
+	}
+}
+
+func TestKindPropagation(t *testing.T) {
+	const kindConflict tracerr.Kind = "conflict"
+	err := tracerr.NewWithKind(tracerr.KindValidation, "invalid email")
+
+	wrapped := tracerr.Wrapf(err, "create user")
+	if tracerr.KindOf(wrapped) != tracerr.KindValidation {
+		t.Errorf("tracerr.KindOf(wrapped) = %#v; want %#v", tracerr.KindOf(wrapped), tracerr.KindValidation)
+	}
+	chained := tracerr.Wrap(fmt.Errorf("handle request: %w", wrapped))
+	if tracerr.KindOf(chained) != tracerr.KindValidation {
+		t.Errorf("tracerr.KindOf(chained) = %#v; want %#v", tracerr.KindOf(chained), tracerr.KindValidation)
+	}
+	if with := tracerr.WithValue(chained, "field", "email"); tracerr.KindOf(with) != tracerr.KindValidation {
+		t.Errorf("tracerr.KindOf(with) = %#v; want %#v", tracerr.KindOf(with), tracerr.KindValidation)
+	}
+	if !tracerr.HasKind(chained, tracerr.KindValidation) {
+		t.Errorf("tracerr.HasKind(chained, KindValidation) = false; want true")
+	}
+	if tracerr.HasKind(chained, tracerr.KindNotFound) {
+		t.Errorf("tracerr.HasKind(chained, KindNotFound) = true; want false")
+	}
+
+	overridden := tracerr.WithKind(wrapped, kindConflict)
+	if tracerr.KindOf(overridden) != kindConflict {
+		t.Errorf("tracerr.KindOf(overridden) = %#v; want %#v", tracerr.KindOf(overridden), kindConflict)
+	}
+	if overridden.Error() != wrapped.Error() {
+		t.Errorf("overridden.Error() = %#v; want %#v", overridden.Error(), wrapped.Error())
+	}
+	if !errors.Is(overridden, err) {
+		t.Errorf("errors.Is(overridden, err) = false; want true")
+	}
+	// Original kind is still found in chain.
+	if !tracerr.HasKind(overridden, kindConflict) || !tracerr.HasKind(overridden, tracerr.KindValidation) {
+		t.Errorf("tracerr.HasKind(overridden, ...) = false; want true")
+	}
+
+	plain := tracerr.WithKind(errors.New("plain error"), tracerr.KindInternal)
+	if tracerr.KindOf(plain) != tracerr.KindInternal || len(plain.StackTrace()) == 0 {
+		t.Errorf("plain = %#v, %#v; want kind with stack trace", tracerr.KindOf(plain), plain.StackTrace())
+	}
+	if tracerr.WithKind(nil, tracerr.KindInternal) != nil {
+		t.Errorf("tracerr.WithKind(nil, ...) != nil")
+	}
+	if tracerr.HasKind(nil, "") {
+		t.Errorf("tracerr.HasKind(nil, \"\") = true; want false")
+	}
+}
+
+func TestSprintWithKinds(t *testing.T) {
+	err := tracerr.NewWithKind(tracerr.KindNotFound, "user not found")
+	output := tracerr.SprintWithOptions(err, tracerr.WithKinds(true))
+	if !strings.HasPrefix(output, "[not_found] user not found\n") {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want kind", output)
+	}
+	if output := tracerr.Sprint(err); !strings.HasPrefix(output, "user not found\n") {
+		t.Errorf("tracerr.Sprint(err) = %#v; want no kind", output)
+	}
+	if output := tracerr.SprintWithOptions(tracerr.New("some error"), tracerr.WithKinds(true)); !strings.HasPrefix(output, "some error\n") {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want no kind", output)
+	}
+}
@@ -0,0 +1,54 @@
+package tracerr
+
+// ExpandFields replaces every error with stack trace in log fields
+// with its message and adds its stack trace as a "<key>_stack" field,
+// which is a list of frames formatted the same way as in Sprint.
+// Errors with no stack trace are kept as is.
+//
+// It has no dependency on logrus, but logrus.Fields can be passed to it,
+// so errors passed to log.WithError(err) can be expanded by a hook.
+// The hook isn't shipped even behind a build tag, since go mod tidy
+// would still add logrus to requirements of this module, so copy it:
+//
+//	import "github.com/sirupsen/logrus"
+//
+//	type tracerrHook struct{}
+//
+//	func (tracerrHook) Levels() []logrus.Level {
+//		return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+//	}
+//
+//	func (tracerrHook) Fire(entry *logrus.Entry) error {
+//		tracerr.ExpandFields(entry.Data)
+//		return nil
+//	}
+//
+//	logrus.AddHook(tracerrHook{})
+func ExpandFields(fields map[string]interface{}) {
+	stacks := make(map[string]interface{})
+	for key, value := range fields {
+		err, ok := value.(error)
+		if !ok {
+			continue
+		}
+		stack := StackStrings(err)
+		if stack == nil {
+			continue
+		}
+		fields[key] = err.Error()
+		stacks[key+"_stack"] = stack
+	}
+	for key, stack := range stacks {
+		fields[key] = stack
+	}
+}
+
+// StackStrings returns frames of err formatted the same way as in Sprint,
+// or nil if err has no stack trace.
+func StackStrings(err error) []string {
+	stackTrace := StackTrace(err)
+	if stackTrace == nil {
+		return nil
+	}
+	return stackStrings(stackTrace)
+}
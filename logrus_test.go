@@ -0,0 +1,59 @@
+package tracerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestExpandFields(t *testing.T) {
+	plain := errors.New("plain error")
+	err := tracerr.CustomError(errors.New("logrus error"), []tracerr.Frame{
+		{Func: "main.foo", Line: 42, Path: "/src/foo.go"},
+		{Func: "main.main", Line: 7, Path: "/src/main.go"},
+	})
+	fields := map[string]interface{}{
+		"error":  err,
+		"cause":  plain,
+		"userID": 42,
+	}
+	tracerr.ExpandFields(fields)
+
+	if fields["error"] != "logrus error" {
+		t.Errorf("fields[\"error\"] = %#v; want %#v", fields["error"], "logrus error")
+	}
+	stack, ok := fields["error_stack"].([]string)
+	expected := []string{
+		"/src/foo.go:42 main.foo()",
+		"/src/main.go:7 main.main()",
+	}
+	if !ok || len(stack) != len(expected) {
+		t.Fatalf("fields[\"error_stack\"] = %#v; want %#v", fields["error_stack"], expected)
+	}
+	for i, frame := range stack {
+		if frame != expected[i] {
+			t.Errorf("stack[%#v] = %#v; want %#v", i, frame, expected[i])
+		}
+	}
+	if fields["cause"] != plain {
+		t.Errorf("fields[\"cause\"] = %#v; want %#v", fields["cause"], plain)
+	}
+	if _, ok := fields["cause_stack"]; ok {
+		t.Errorf("fields[\"cause_stack\"] is set; want no field")
+	}
+	if fields["userID"] != 42 {
+		t.Errorf("fields[\"userID\"] = %#v; want %#v", fields["userID"], 42)
+	}
+}
+
+func TestStackStrings(t *testing.T) {
+	if stack := tracerr.StackStrings(errors.New("plain")); stack != nil {
+		t.Errorf("tracerr.StackStrings(plain) = %#v; want nil", stack)
+	}
+	err := addFrameA("stack error")
+	stack := tracerr.StackStrings(err)
+	if len(stack) != len(tracerr.StackTrace(err)) {
+		t.Errorf("len(stack) = %#v; want %#v", len(stack), len(tracerr.StackTrace(err)))
+	}
+}
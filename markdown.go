@@ -0,0 +1,88 @@
+package tracerr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"<", `\<`,
+)
+
+// SprintMarkdown returns error output as Markdown, such as for GitHub issues:
+// error message in bold, each frame as a list item
+// and source fragment in a fenced code block with traced line marked by ">".
+// Number of source lines is defined by the same rules as in PrintSource.
+//
+//	**some error**
+//
+//	- `/src/main.go:42 main.foo()`
+//
+//	  ```go
+//	    41 | func foo() {
+//	  > 42 | 	panic("boom")
+//	    43 | }
+//	  ```
+func SprintMarkdown(err error, nums ...int) string {
+	if err == nil {
+		return ""
+	}
+	rows := []string{
+		"**" + markdownEscaper.Replace(err.Error()) + "**",
+	}
+	e, ok := err.(Error)
+	if !ok {
+		return rows[0]
+	}
+	o := newOptions([]Option{WithSource(nums...)})
+	before, after, withSource := calcRows(o.nums)
+	rows = append(rows, "")
+	for _, frame := range o.ordered(o.selected(e.StackTrace())) {
+		rows = append(rows, "- `"+frame.String()+"`")
+		if withSource {
+			rows = append(rows, "")
+			rows = markdownSource(rows, frame.Frame, before, after)
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+func markdownSource(rows []string, frame Frame, before, after int) []string {
+	window, err := sourceWindow(frame, before, after)
+	if err != nil {
+		message := sourceErrorMessage(frame, err)
+		if message == "" {
+			return rows
+		}
+		return append(rows, "  *"+markdownEscaper.Replace(message)+"*", "")
+	}
+	if len(window) == 0 {
+		return rows
+	}
+	width := len(strconv.Itoa(window[len(window)-1].Number))
+	// Fence must be longer than any backtick sequence in source.
+	fence := "```"
+	for _, line := range window {
+		for strings.Contains(line.Text, fence) {
+			fence += "`"
+		}
+	}
+	lang := ""
+	if strings.HasSuffix(frame.Path, ".go") {
+		lang = "go"
+	}
+	rows = append(rows, "  "+fence+lang)
+	for _, line := range window {
+		marker := " "
+		if line.Traced {
+			marker = ">"
+		}
+		rows = append(rows, fmt.Sprintf("  %s %*d | %s", marker, width, line.Number, line.Text))
+	}
+	return append(rows, "  "+fence, "")
+}
@@ -0,0 +1,81 @@
+package tracerr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestSprintMarkdown(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/main.go": {
+			Data: []byte("package main\n\nfunc main() {\n\tpanic(\"boom\")\n}\n"),
+		},
+	})
+	err := tracerr.CustomError(
+		errors.New("some *error*"),
+		[]tracerr.Frame{
+			{
+				Func: "main.main",
+				Line: 4,
+				Path: "/src/main.go",
+			},
+			{
+				Func: "main.foo",
+				Line: 1,
+				Path: "/src/foo.go",
+			},
+			{
+				Func: "runtime.main",
+				Line: 1,
+				Path: "/src/runtime.go",
+			},
+		},
+	)
+	output := tracerr.SprintMarkdown(err, 1, 1)
+	expected := strings.Join([]string{
+		`**some \*error\***`,
+		"",
+		"- `/src/main.go:4 main.main()`",
+		"",
+		"  ```go",
+		"    3 | func main() {",
+		"  > 4 | \tpanic(\"boom\")",
+		"    5 | }",
+		"  ```",
+		"",
+		"- `/src/foo.go:1 main.foo()`",
+		"",
+		"  *tracerr: file /src/foo.go not found*",
+		"",
+		"- `/src/runtime.go:1 runtime.main()`",
+		"",
+		"  *tracerr: file /src/runtime.go not found*",
+		"",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintMarkdown(err, 1, 1) = %#v; want %#v", output, expected)
+	}
+
+	expected = strings.Join([]string{
+		`**some \*error\***`,
+		"",
+		"- `/src/main.go:4 main.main()`",
+		"- `/src/foo.go:1 main.foo()`",
+	}, "\n")
+	defer func() {
+		tracerr.SetDefaultMaxFrames(0)
+	}()
+	tracerr.SetDefaultMaxFrames(2)
+	if output := tracerr.SprintMarkdown(err, 0); output != expected {
+		t.Errorf("tracerr.SprintMarkdown(err, 0) = %#v; want %#v", output, expected)
+	}
+
+	if output := tracerr.SprintMarkdown(errors.New("regular_error")); output != `**regular\_error**` {
+		t.Errorf("tracerr.SprintMarkdown(err) = %#v; want %#v", output, `**regular\_error**`)
+	}
+}
@@ -0,0 +1,577 @@
+package tracerr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxFrames is a maximum number of frames to display,
+// 0 means no limit.
+//
+// Deprecated: Use SetDefaultMaxFrames, which is safe for concurrent use.
+// Assigning the variable while other goroutines print errors is a data race.
+var DefaultMaxFrames = 0
+
+// DefaultIgnoreFirstFrames is a number of innermost frames to skip in output.
+//
+// Deprecated: Use SetDefaultIgnoreFrames, which is safe for concurrent use.
+// Assigning the variable while other goroutines print errors is a data race.
+var DefaultIgnoreFirstFrames = 0
+
+// DefaultIgnoreLastFrames is a number of outermost frames to skip in output.
+//
+// Deprecated: Use SetDefaultIgnoreFrames, which is safe for concurrent use.
+// Assigning the variable while other goroutines print errors is a data race.
+var DefaultIgnoreLastFrames = 0
+
+// DefaultReverseFrames defines whether frames are displayed
+// from outermost to innermost.
+//
+// Deprecated: Use SetDefaultReverseFrames, which is safe for concurrent use.
+// Assigning the variable while other goroutines print errors is a data race.
+var DefaultReverseFrames = false
+
+// DefaultSyntaxHighlight defines whether Go source fragments
+// are syntax highlighted in colorized output.
+//
+// Deprecated: Use SetDefaultSyntaxHighlight, which is safe for concurrent use.
+// Assigning the variable while other goroutines print errors is a data race.
+var DefaultSyntaxHighlight = false
+
+// Option configures output of SprintWithOptions.
+// Options which are not passed default to corresponding package variables.
+type Option func(*options)
+
+type options struct {
+	nums              []int
+	colorized         bool
+	maxFrames         int
+	ignoreFirstFrames int
+	ignoreLastFrames  int
+	withTimestamp     bool
+	runtimeInfo       bool
+	withValues        bool
+	withKinds         bool
+	filter            func(Frame) bool
+	transform         func(Frame) Frame
+	reverseFrames     bool
+	syntaxHighlight   bool
+	collapseRepeats   bool
+	collapseBelow     string
+	sourceFirstOnly   bool
+	lineBudget        int
+	compactSpacing    bool
+	staleWarning      bool
+	alignHeaders      bool
+	mergeInlined      bool
+	dedentSource      bool
+	fitTerminal       bool
+	position          Position
+	frameSeparator    string
+	tree              bool
+	mergeSource       bool
+}
+
+func newOptions(opts []Option) *options {
+	configMutex.RLock()
+	o := &options{
+		nums:              []int{0},
+		maxFrames:         DefaultMaxFrames,
+		ignoreFirstFrames: DefaultIgnoreFirstFrames,
+		ignoreLastFrames:  DefaultIgnoreLastFrames,
+		filter:            getFrameFilter(),
+		transform:         getFrameTransform(),
+		reverseFrames:     DefaultReverseFrames,
+		syntaxHighlight:   DefaultSyntaxHighlight,
+		frameSeparator:    DefaultFrameSeparator,
+	}
+	configMutex.RUnlock()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithSource adds source fragments to output,
+// nums are the same as in PrintSource.
+func WithSource(nums ...int) Option {
+	return func(o *options) {
+		o.nums = nums
+	}
+}
+
+// WithColor defines whether output is in color.
+func WithColor(colorized bool) Option {
+	return func(o *options) {
+		o.colorized = colorized
+	}
+}
+
+// WithMaxFrames sets a maximum number of frames to display,
+// 0 means no limit.
+func WithMaxFrames(n int) Option {
+	return func(o *options) {
+		o.maxFrames = n
+	}
+}
+
+// WithIgnoreFirstFrames sets a number of innermost frames to skip in output.
+func WithIgnoreFirstFrames(n int) Option {
+	return func(o *options) {
+		o.ignoreFirstFrames = n
+	}
+}
+
+// WithIgnoreLastFrames sets a number of outermost frames to skip in output.
+func WithIgnoreLastFrames(n int) Option {
+	return func(o *options) {
+		o.ignoreLastFrames = n
+	}
+}
+
+// WithTimestamp adds time of error creation to output, if it's recorded.
+func WithTimestamp(enabled bool) Option {
+	return func(o *options) {
+		o.withTimestamp = enabled
+	}
+}
+
+// WithRuntimeInfo adds Go version, OS and architecture to output,
+// such as "go1.22.1 linux/amd64", so stack trace pasted to a bug report is self-describing.
+func WithRuntimeInfo(enabled bool) Option {
+	return func(o *options) {
+		o.runtimeInfo = enabled
+	}
+}
+
+// WithValues adds metadata attached by WithValue to output
+// as "key=value" pairs sorted by key, in a line after error message.
+func WithValues(enabled bool) Option {
+	return func(o *options) {
+		o.withValues = enabled
+	}
+}
+
+// WithKinds defines whether error message is preceded by kind,
+// set by NewWithKind or WithKind, such as "[not_found] user not found".
+func WithKinds(enabled bool) Option {
+	return func(o *options) {
+		o.withKinds = enabled
+	}
+}
+
+// WithReversedFrames defines whether frames are displayed
+// from outermost to innermost.
+//
+// Frames to ignore are still counted from innermost,
+// so the same frames are displayed regardless of order.
+func WithReversedFrames(reversed bool) Option {
+	return func(o *options) {
+		o.reverseFrames = reversed
+	}
+}
+
+// WithSyntaxHighlight defines whether source fragments of .go files
+// are syntax highlighted in colorized output.
+// Keywords, strings and comments are colored,
+// except of traced line, which is highlighted as a whole.
+func WithSyntaxHighlight(enabled bool) Option {
+	return func(o *options) {
+		o.syntaxHighlight = enabled
+	}
+}
+
+// displayFrame is a frame prepared for output.
+type displayFrame struct {
+	Frame
+	// repeated is a number of identical consecutive frames collapsed into this one.
+	repeated int
+	// index is a position among displayed frames, innermost first.
+	index int
+	// hidden is a number of frames collapsed into a summary line,
+	// it's 0 for regular frames.
+	hidden int
+	// callers contains functions of frames merged into this one, which share the same line,
+	// such as inlined calls, from the nearest to the outermost.
+	callers []string
+}
+
+// String formats displayFrame to frame header.
+func (f displayFrame) String() string {
+	if f.hidden == 1 {
+		return "... (1 frame in dependencies)"
+	}
+	if f.hidden > 1 {
+		return fmt.Sprintf("... (%d frames in dependencies)", f.hidden)
+	}
+	// Functions of merged frames are displayed by default format only,
+	// custom formatter gets the innermost frame as is.
+	var header string
+	if formatter := getFrameFormatter(); formatter != nil {
+		header = formatter(f.Frame)
+	} else {
+		header = fmt.Sprintf("%s:%d %s()", displayPath(f.Path), f.Line, f.funcChain())
+	}
+	if f.repeated > 1 {
+		header += fmt.Sprintf(" (repeated %d times)", f.repeated)
+	}
+	return header
+}
+
+// WithOnlyPackages defines that only frames of provided packages are displayed,
+// the same way as OnlyPackages filter does.
+// It's applied together with filter set by SetFrameFilter.
+//
+// Frames are filtered before DefaultMaxFrames cap, so only kept frames are counted.
+func WithOnlyPackages(prefixes ...string) Option {
+	only := OnlyPackages(prefixes...)
+	return func(o *options) {
+		filter := o.filter
+		if filter == nil {
+			o.filter = only
+			return
+		}
+		o.filter = func(frame Frame) bool {
+			return filter(frame) && only(frame)
+		}
+	}
+}
+
+// WithSourceOnlyFirstFrame defines whether source fragment is displayed
+// only for the innermost frame, where error was created,
+// and other frames are displayed with no source.
+// It's the innermost frame regardless of WithReversedFrames.
+func WithSourceOnlyFirstFrame(enabled bool) Option {
+	return func(o *options) {
+		o.sourceFirstOnly = enabled
+	}
+}
+
+// WithCompactSpacing defines whether empty lines, which separate frames with source
+// and errors joined by Join, are omitted from output.
+func WithCompactSpacing(enabled bool) Option {
+	return func(o *options) {
+		o.compactSpacing = enabled
+	}
+}
+
+// WithTree defines whether errors joined by Join or grouped by Group
+// are displayed as branches of a tree, with output of each error indented under its branch:
+//
+//	2 errors occurred:
+//	│
+//	├─ first error
+//	│  /src/main.go:10 main.foo()
+//	│
+//	└─ second error
+//	   /src/main.go:20 main.bar()
+//
+// Single errors are displayed the same way either way.
+func WithTree(enabled bool) Option {
+	return func(o *options) {
+		o.tree = enabled
+	}
+}
+
+// WithMergedSource defines whether consecutive frames in the same file
+// with overlapping or close source fragments share a single fragment,
+// which is displayed after the last of them with traced lines of all of them,
+// so tight call chains within one file don't repeat the same lines.
+// Lines skipped between fragments are replaced with a single "..." line.
+func WithMergedSource(enabled bool) Option {
+	return func(o *options) {
+		o.mergeSource = enabled
+	}
+}
+
+// DefaultFrameSeparator separates frames in single line output, such as SprintCompact.
+const DefaultFrameSeparator = " → "
+
+// WithFrameSeparator sets a separator of frames in single line output, such as SprintCompact,
+// which is used as is, so it should include spaces if needed, like " -> " or " | ".
+// It helps with terminals and log systems which don't support UTF-8.
+// Multi-line output, including headers of frames merged by WithMergeInlined, is not affected.
+func WithFrameSeparator(separator string) Option {
+	return func(o *options) {
+		o.frameSeparator = separator
+	}
+}
+
+// WithStaleSourceWarning defines whether source fragment is preceded by a warning,
+// if source file is modified after running binary was built, so it may not match frame lines.
+//
+// Build time is told by modification time of the binary, or by process start time
+// if binary could not be found. It's a heuristic: copying or touching files
+// changes their modification time with no changes to content,
+// and files with unknown modification time, such as embedded ones, are never reported.
+func WithStaleSourceWarning(enabled bool) Option {
+	return func(o *options) {
+		o.staleWarning = enabled
+	}
+}
+
+// WithAlignedHeaders defines whether frame headers are aligned in columns,
+// so functions of all frames start at the same position.
+// It's not applied if frame format is changed by SetFrameFormatter.
+func WithAlignedHeaders(enabled bool) Option {
+	return func(o *options) {
+		o.alignHeaders = enabled
+	}
+}
+
+// WithMergeInlined defines whether consecutive frames of different functions,
+// which point to the same line, such as inlined calls in optimized builds,
+// are merged into one frame with a header like "main.go:10 outer() → inlined()".
+// Frame formatter set by SetFrameFormatter gets the innermost frame only.
+// Recursive calls of the same function are not merged.
+func WithMergeInlined(enabled bool) Option {
+	return func(o *options) {
+		o.mergeInlined = enabled
+	}
+}
+
+// WithDedentSource defines whether leading whitespace, which is common for all lines
+// of source fragment, is removed, so deeply nested code isn't pushed to the right.
+// Relative indentation of lines is kept.
+func WithDedentSource(enabled bool) Option {
+	return func(o *options) {
+		o.dedentSource = enabled
+	}
+}
+
+// WithTerminalWidth defines whether source rows are fitted into width of terminal,
+// which is returned by TerminalWidth, long lines are truncated with an ellipsis.
+// If SetMaxSourceLineWidth is set too, the smaller width is used.
+func WithTerminalWidth(enabled bool) Option {
+	return func(o *options) {
+		o.fitTerminal = enabled
+	}
+}
+
+// Position defines how position of traced line within its file is displayed in frame headers.
+type Position int
+
+const (
+	// PositionNone displays no position, which is a default.
+	PositionNone Position = iota
+	// PositionOffset displays byte offset of traced line, such as "(offset 10234)".
+	PositionOffset
+	// PositionPercent displays percentage of file before traced line, such as "(42%)".
+	PositionPercent
+)
+
+// WithPosition defines whether frame headers are followed by position of traced line
+// within its file, such as for tools which don't understand line numbers.
+// It's computed from source file, so file is read even with no source in output.
+func WithPosition(position Position) Option {
+	return func(o *options) {
+		o.position = position
+	}
+}
+
+// WithTotalLineBudget sets a maximum total number of source lines
+// displayed for all frames, such as to fit output in a terminal height.
+// Source lines go to innermost frames first,
+// so outermost frames get smaller fragments or no source at all,
+// but all frame headers are still displayed.
+// If n <= 0 there is no limit, which is a default.
+func WithTotalLineBudget(n int) Option {
+	return func(o *options) {
+		o.lineBudget = n
+	}
+}
+
+// sourceBudget defines source fragment of a frame.
+type sourceBudget struct {
+	// before is a number of source lines before traced line.
+	before int
+	// after is a number of source lines after traced line.
+	after int
+	// shown is false if frame is displayed with no source.
+	shown bool
+}
+
+// sourceBudgets returns source fragments of frames indexed the same way as displayFrame.
+func (o *options) sourceBudgets(frames int, before, after int) []sourceBudget {
+	budgets := make([]sourceBudget, frames)
+	lines := before + after + 1
+	remaining := o.lineBudget
+	for i := range budgets {
+		if o.sourceFirstOnly && i > 0 {
+			continue
+		}
+		if o.lineBudget <= 0 {
+			budgets[i] = sourceBudget{before: before, after: after, shown: true}
+			continue
+		}
+		n := lines
+		if n > remaining {
+			n = remaining
+		}
+		remaining -= n
+		if n <= 0 {
+			continue
+		}
+		// Traced line is kept, the rest is split between lines before and after.
+		a := after
+		if n < lines {
+			a = (n - 1) / 2
+			if a > after {
+				a = after
+			}
+			if n-1-a > before {
+				a = n - 1 - before
+			}
+		}
+		budgets[i] = sourceBudget{before: n - 1 - a, after: a, shown: true}
+	}
+	return budgets
+}
+
+// WithCollapseRepeats defines whether runs of identical consecutive frames,
+// such as in recursion, are collapsed into a single frame
+// annotated with a number of repeats.
+//
+// Frames are collapsed after filtering and before DefaultMaxFrames cap.
+func WithCollapseRepeats(enabled bool) Option {
+	return func(o *options) {
+		o.collapseRepeats = enabled
+	}
+}
+
+// WithCollapseBelowPackage defines that frames are displayed
+// up to the first frame of a function outside of package with provided import path prefix,
+// starting from the innermost frame,
+// and the rest of frames are collapsed into a single summary line
+// with a number of hidden frames, such as "... (12 frames in dependencies)".
+// Subpackages are kept as well.
+//
+// Frames are collapsed after filtering, and DefaultMaxFrames cap
+// is applied only to displayed frames. Frames over the cap are counted in the summary,
+// as well as frames merged by WithCollapseRepeats and WithMergeInlined.
+func WithCollapseBelowPackage(prefix string) Option {
+	return func(o *options) {
+		o.collapseBelow = prefix
+	}
+}
+
+// frames returns frames to display, innermost first.
+func (o *options) frames(frames []Frame) []Frame {
+	selected := o.selected(frames)
+	plain := make([]Frame, 0, len(selected))
+	for _, frame := range selected {
+		if frame.hidden > 0 {
+			continue
+		}
+		plain = append(plain, frame.Frame)
+	}
+	return plain
+}
+
+// selected returns frames to display, innermost first.
+// Frames are ignored by position first, then filtered,
+// collapsed and capped, so the cap counts only displayed frames.
+func (o *options) selected(frames []Frame) []displayFrame {
+	first := o.ignoreFirstFrames
+	if first < 0 {
+		first = 0
+	}
+	last := len(frames) - o.ignoreLastFrames
+	if last > len(frames) {
+		last = len(frames)
+	}
+	if first >= last {
+		return nil
+	}
+	frames = frames[first:last]
+	selected := make([]displayFrame, 0, len(frames))
+	for _, frame := range frames {
+		if o.filter != nil && !o.filter(frame) {
+			continue
+		}
+		if o.transform != nil {
+			frame = o.transform(frame)
+		}
+		if o.collapseRepeats && len(selected) > 0 && selected[len(selected)-1].Frame == frame {
+			selected[len(selected)-1].repeated++
+			continue
+		}
+		if o.mergeInlined && len(selected) > 0 && selected[len(selected)-1].inlines(frame) {
+			prev := &selected[len(selected)-1]
+			prev.callers = append(prev.callers, frame.Func)
+			continue
+		}
+		selected = append(selected, displayFrame{
+			Frame:    frame,
+			repeated: 1,
+		})
+	}
+	shown := len(selected)
+	collapsed := false
+	if o.collapseBelow != "" {
+		for i, frame := range selected {
+			if !inPackage(frame.Func, o.collapseBelow) {
+				shown, collapsed = i, true
+				break
+			}
+		}
+	}
+	if o.maxFrames > 0 && shown > o.maxFrames {
+		shown = o.maxFrames
+	}
+	// Summary counts all frames it replaces, including repeated and merged ones.
+	var summary displayFrame
+	for _, hidden := range selected[shown:] {
+		summary.hidden += hidden.repeated + len(hidden.callers)
+	}
+	selected = selected[:shown]
+	if collapsed {
+		selected = append(selected, summary)
+	}
+	for i := range selected {
+		selected[i].index = i
+	}
+	return selected
+}
+
+// funcChain returns function name, preceded by functions of merged frames,
+// from the outermost one, such as "outer() → inlined".
+func (f displayFrame) funcChain() string {
+	if len(f.callers) == 0 {
+		return f.Func
+	}
+	chain := make([]string, 0, len(f.callers)+1)
+	for i := len(f.callers) - 1; i >= 0; i-- {
+		chain = append(chain, f.callers[i])
+	}
+	return strings.Join(append(chain, f.Func), "() → ")
+}
+
+// inlines reports whether f and its caller frame share the same line of different functions,
+// as inlined calls do. Recursive calls of the same function aren't merged.
+func (f displayFrame) inlines(caller Frame) bool {
+	if f.repeated > 1 || f.Path != caller.Path || f.Line != caller.Line {
+		return false
+	}
+	if f.Func == caller.Func {
+		return false
+	}
+	for _, fn := range f.callers {
+		if fn == caller.Func {
+			return false
+		}
+	}
+	return true
+}
+
+// ordered returns frames in display order.
+func (o *options) ordered(frames []displayFrame) []displayFrame {
+	if !o.reverseFrames {
+		return frames
+	}
+	reversed := make([]displayFrame, len(frames))
+	for i, frame := range frames {
+		reversed[len(frames)-1-i] = frame
+	}
+	return reversed
+}
@@ -0,0 +1,571 @@
+package tracerr_test
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+type OptionsTestCase struct {
+	Options      []tracerr.Option
+	ExpectedRows []string
+}
+
+func TestSprintWithOptions(t *testing.T) {
+	err := addFrameA("options error")
+	frames := len(tracerr.StackTrace(err))
+
+	cases := []OptionsTestCase{
+		{
+			Options: []tracerr.Option{
+				tracerr.WithMaxFrames(2),
+			},
+			ExpectedRows: []string{
+				"options error",
+				"/tracerr/error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+				"/tracerr/error_helper_test.go:13 github.com/ztrue/tracerr_test.addFrameB()",
+			},
+		},
+		{
+			Options: []tracerr.Option{
+				tracerr.WithIgnoreFirstFrames(1),
+				tracerr.WithMaxFrames(2),
+			},
+			ExpectedRows: []string{
+				"options error",
+				"/tracerr/error_helper_test.go:13 github.com/ztrue/tracerr_test.addFrameB()",
+				"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
+			},
+		},
+		{
+			Options: []tracerr.Option{
+				tracerr.WithIgnoreFirstFrames(2),
+				tracerr.WithIgnoreLastFrames(frames - 3),
+			},
+			ExpectedRows: []string{
+				"options error",
+				"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
+			},
+		},
+		{
+			Options: []tracerr.Option{
+				tracerr.WithIgnoreFirstFrames(frames),
+			},
+			ExpectedRows: []string{
+				"options error",
+			},
+		},
+		{
+			Options: []tracerr.Option{
+				tracerr.WithMaxFrames(1),
+				tracerr.WithSource(0, 1),
+			},
+			ExpectedRows: []string{
+				"options error",
+				"",
+				"/tracerr/error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+				"17\t\treturn tracerr.New(message)",
+				"18\t}",
+			},
+		},
+		{
+			Options: []tracerr.Option{
+				tracerr.WithMaxFrames(1),
+				tracerr.WithSource(0, 0),
+				tracerr.WithColor(true),
+			},
+			ExpectedRows: []string{
+				"options error",
+				"",
+				bold("/tracerr/error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()"),
+				red("17\t\treturn tracerr.New(message)"),
+			},
+		},
+	}
+
+	for i, c := range cases {
+		output := tracerr.SprintWithOptions(err, c.Options...)
+		assertRows(t, i, output, c.ExpectedRows, 0)
+		rows := strings.Split(output, "\n")
+		if len(rows) != len(c.ExpectedRows) {
+			t.Errorf(
+				"case #%d: len(rows) = %#v; want %#v",
+				i, len(rows), len(c.ExpectedRows),
+			)
+		}
+	}
+}
+
+func TestDefaultMaxFrames(t *testing.T) {
+	defer func() {
+		tracerr.SetDefaultMaxFrames(0)
+	}()
+	tracerr.SetDefaultMaxFrames(1)
+	err := addFrameA("options error")
+
+	output := tracerr.Sprint(err)
+	assertRows(t, 0, output, []string{
+		"options error",
+		"/tracerr/error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+	}, 0)
+	if rows := strings.Split(output, "\n"); len(rows) != 2 {
+		t.Errorf("len(rows) = %#v; want %#v", len(rows), 2)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithMaxFrames(2))
+	if rows := strings.Split(output, "\n"); len(rows) != 3 {
+		t.Errorf("len(rows) = %#v; want %#v", len(rows), 3)
+	}
+}
+
+func TestReversedFrames(t *testing.T) {
+	defer func() {
+		tracerr.SetDefaultReverseFrames(false)
+	}()
+	err := addFrameA("options error")
+	frames := len(tracerr.StackTrace(err))
+	expectedRows := []string{
+		"options error",
+		"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
+		"/tracerr/error_helper_test.go:13 github.com/ztrue/tracerr_test.addFrameB()",
+	}
+
+	output := tracerr.SprintWithOptions(
+		err,
+		tracerr.WithReversedFrames(true),
+		tracerr.WithIgnoreFirstFrames(1),
+		tracerr.WithIgnoreLastFrames(frames-3),
+	)
+	assertRows(t, 0, output, expectedRows, 0)
+	if rows := strings.Split(output, "\n"); len(rows) != len(expectedRows) {
+		t.Errorf("len(rows) = %#v; want %#v", len(rows), len(expectedRows))
+	}
+
+	tracerr.SetDefaultReverseFrames(true)
+	tracerr.SetDefaultIgnoreFrames(1, frames-3)
+	defer func() {
+		tracerr.SetDefaultIgnoreFrames(0, 0)
+	}()
+	output = tracerr.Sprint(err)
+	assertRows(t, 1, output, expectedRows, 0)
+	if rows := strings.Split(output, "\n"); len(rows) != len(expectedRows) {
+		t.Errorf("len(rows) = %#v; want %#v", len(rows), len(expectedRows))
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithReversedFrames(false))
+	assertRows(t, 2, output, []string{
+		"options error",
+		"/tracerr/error_helper_test.go:13 github.com/ztrue/tracerr_test.addFrameB()",
+		"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
+	}, 0)
+}
+
+func TestSprintWithCollapseRepeats(t *testing.T) {
+	recurse := tracerr.Frame{Func: "main.recurse", Line: 12, Path: "/src/main.go"}
+	frames := []tracerr.Frame{
+		{Func: "main.leaf", Line: 5, Path: "/src/main.go"},
+		recurse,
+		recurse,
+		recurse,
+		{Func: "main.main", Line: 20, Path: "/src/main.go"},
+	}
+	err := tracerr.CustomError(errors.New("recursion error"), frames)
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithCollapseRepeats(true))
+	expected := strings.Join([]string{
+		"recursion error",
+		"/src/main.go:5 main.leaf()",
+		"/src/main.go:12 main.recurse() (repeated 3 times)",
+		"/src/main.go:20 main.main()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(
+		err,
+		tracerr.WithCollapseRepeats(true),
+		tracerr.WithMaxFrames(2),
+	)
+	expected = strings.Join([]string{
+		"recursion error",
+		"/src/main.go:5 main.leaf()",
+		"/src/main.go:12 main.recurse() (repeated 3 times)",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.Sprint(err)
+	if rows := strings.Split(output, "\n"); len(rows) != len(frames)+1 {
+		t.Errorf("len(rows) = %#v; want %#v", len(rows), len(frames)+1)
+	}
+}
+
+func TestSprintWithSourceOnlyFirstFrame(t *testing.T) {
+	err := addFrameA("first frame error")
+	output := tracerr.SprintWithOptions(
+		err,
+		tracerr.WithSource(0, 0),
+		tracerr.WithSourceOnlyFirstFrame(true),
+		tracerr.WithMaxFrames(3),
+	)
+	expectedRows := []string{
+		"first frame error",
+		"",
+		"/tracerr/error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"17\t\treturn tracerr.New(message)",
+		"",
+		"/tracerr/error_helper_test.go:13 github.com/ztrue/tracerr_test.addFrameB()",
+		"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
+	}
+	assertRows(t, 0, output, expectedRows, 0)
+	if rows := strings.Split(output, "\n"); len(rows) != len(expectedRows) {
+		t.Errorf("len(rows) = %#v; want %#v", len(rows), len(expectedRows))
+	}
+
+	// The innermost frame has source in reversed order as well.
+	output = tracerr.SprintWithOptions(
+		err,
+		tracerr.WithSource(0, 0),
+		tracerr.WithSourceOnlyFirstFrame(true),
+		tracerr.WithMaxFrames(3),
+		tracerr.WithReversedFrames(true),
+	)
+	expectedRows = []string{
+		"first frame error",
+		"",
+		"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
+		"/tracerr/error_helper_test.go:13 github.com/ztrue/tracerr_test.addFrameB()",
+		"",
+		"/tracerr/error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"17\t\treturn tracerr.New(message)",
+	}
+	assertRows(t, 1, output, expectedRows, 0)
+	if rows := strings.Split(output, "\n"); len(rows) != len(expectedRows) {
+		t.Errorf("len(rows) = %#v; want %#v", len(rows), len(expectedRows))
+	}
+}
+
+func TestSprintWithTotalLineBudget(t *testing.T) {
+	err := addFrameA("budget error")
+	output := tracerr.SprintWithOptions(
+		err,
+		tracerr.WithSource(3, 2),
+		tracerr.WithTotalLineBudget(8),
+		tracerr.WithMaxFrames(3),
+	)
+	expectedRows := []string{
+		"budget error",
+		"",
+		"/tracerr/error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"14\t}",
+		"15\t",
+		"16\tfunc addFrameC(message string) error {",
+		"17\t\treturn tracerr.New(message)",
+		"18\t}",
+		"19\t",
+		"",
+		"/tracerr/error_helper_test.go:13 github.com/ztrue/tracerr_test.addFrameB()",
+		"12\tfunc addFrameB(message string) error {",
+		"13\t\treturn addFrameC(message)",
+		"",
+		"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
+	}
+	assertRows(t, 0, output, expectedRows, 0)
+	if rows := strings.Split(output, "\n"); len(rows) != len(expectedRows) {
+		t.Errorf("len(rows) = %#v; want %#v", len(rows), len(expectedRows))
+	}
+
+	sourceLine := regexp.MustCompile(`^\d+\t`)
+	frames := len(tracerr.StackTrace(err))
+	for budget := 1; budget <= 30; budget++ {
+		output := tracerr.SprintWithOptions(
+			err,
+			tracerr.WithSource(3, 2),
+			tracerr.WithTotalLineBudget(budget),
+		)
+		lines, headers := 0, 0
+		for _, row := range strings.Split(output, "\n") {
+			if sourceLine.MatchString(row) {
+				lines++
+			} else if strings.Contains(row, "()") {
+				headers++
+			}
+		}
+		if lines > budget {
+			t.Errorf("budget %#v: source lines = %#v; want <= %#v", budget, lines, budget)
+		}
+		if headers != frames {
+			t.Errorf("budget %#v: headers = %#v; want %#v", budget, headers, frames)
+		}
+	}
+}
+
+func TestSprintWithCollapseBelowPackage(t *testing.T) {
+	err := tracerr.CustomError(
+		errors.New("collapse error"),
+		[]tracerr.Frame{
+			{Func: "github.com/john/doe/store.(*DB).Get", Line: 1, Path: "/src/doe/store/db.go"},
+			{Func: "github.com/john/doe.handle", Line: 2, Path: "/src/doe/handle.go"},
+			{Func: "net/http.HandlerFunc.ServeHTTP", Line: 3, Path: "/go/src/net/http/server.go"},
+			{Func: "net/http.(*conn).serve", Line: 4, Path: "/go/src/net/http/server.go"},
+			{Func: "runtime.goexit", Line: 5, Path: "/go/src/runtime/asm_amd64.s"},
+		},
+	)
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithCollapseBelowPackage("github.com/john/doe"))
+	expected := strings.Join([]string{
+		"collapse error",
+		"/src/doe/store/db.go:1 github.com/john/doe/store.(*DB).Get()",
+		"/src/doe/handle.go:2 github.com/john/doe.handle()",
+		"... (3 frames in dependencies)",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	// Summary has no source and goes first in reversed order.
+	output = tracerr.SprintWithOptions(
+		err,
+		tracerr.WithCollapseBelowPackage("github.com/john/doe/store"),
+		tracerr.WithReversedFrames(true),
+		tracerr.WithSource(0, 0),
+	)
+	expected = strings.Join([]string{
+		"collapse error",
+		"",
+		"... (4 frames in dependencies)",
+		"",
+		"/src/doe/store/db.go:1 github.com/john/doe/store.(*DB).Get()",
+		"tracerr: file /src/doe/store/db.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithCollapseBelowPackage("github.com/jane"))
+	expected = strings.Join([]string{
+		"collapse error",
+		"... (5 frames in dependencies)",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	// Frames over the cap are counted.
+	output = tracerr.SprintWithOptions(
+		err,
+		tracerr.WithCollapseBelowPackage("github.com/john/doe"),
+		tracerr.WithMaxFrames(1),
+	)
+	expected = strings.Join([]string{
+		"collapse error",
+		"/src/doe/store/db.go:1 github.com/john/doe/store.(*DB).Get()",
+		"... (4 frames in dependencies)",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	// Merged frames are counted.
+	err = tracerr.CustomError(
+		errors.New("collapse error"),
+		[]tracerr.Frame{
+			{Func: "github.com/john/doe.handle", Line: 2, Path: "/src/doe/handle.go"},
+			{Func: "net/http.HandlerFunc.ServeHTTP", Line: 3, Path: "/go/src/net/http/server.go"},
+			{Func: "net/http.(*conn).serve", Line: 3, Path: "/go/src/net/http/server.go"},
+			{Func: "runtime.goexit", Line: 5, Path: "/go/src/runtime/asm_amd64.s"},
+		},
+	)
+	output = tracerr.SprintWithOptions(
+		err,
+		tracerr.WithCollapseBelowPackage("github.com/john/doe"),
+		tracerr.WithMergeInlined(true),
+	)
+	expected = strings.Join([]string{
+		"collapse error",
+		"/src/doe/handle.go:2 github.com/john/doe.handle()",
+		"... (3 frames in dependencies)",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+}
+
+func TestWithCompactSpacing(t *testing.T) {
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameC",
+				Line: 17,
+				Path: "error_helper_test.go",
+			},
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameB",
+				Line: 13,
+				Path: "error_helper_test.go",
+			},
+		},
+	)
+	spaced := strings.Join([]string{
+		"some error",
+		"",
+		"error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"16\tfunc addFrameC(message string) error {",
+		"17\t\treturn tracerr.New(message)",
+		"18\t}",
+		"",
+		"error_helper_test.go:13 github.com/ztrue/tracerr_test.addFrameB()",
+		"12\tfunc addFrameB(message string) error {",
+		"13\t\treturn addFrameC(message)",
+		"14\t}",
+	}, "\n")
+	compact := strings.Join([]string{
+		"some error",
+		"error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"16\tfunc addFrameC(message string) error {",
+		"17\t\treturn tracerr.New(message)",
+		"18\t}",
+		"error_helper_test.go:13 github.com/ztrue/tracerr_test.addFrameB()",
+		"12\tfunc addFrameB(message string) error {",
+		"13\t\treturn addFrameC(message)",
+		"14\t}",
+	}, "\n")
+	output := tracerr.SprintWithOptions(err, tracerr.WithSource(1, 1), tracerr.WithCompactSpacing(false))
+	if output != spaced {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, spaced)
+	}
+	output = tracerr.SprintWithOptions(err, tracerr.WithSource(1, 1), tracerr.WithCompactSpacing(true))
+	if output != compact {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, compact)
+	}
+
+	joined := tracerr.Join(tracerr.New("error 1"), tracerr.New("error 2"))
+	output = tracerr.SprintWithOptions(joined, tracerr.WithCompactSpacing(true))
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		if line == "" {
+			t.Errorf("line %#v of joined output %#v is empty", i, output)
+		}
+	}
+}
+
+func TestWithAlignedHeaders(t *testing.T) {
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{Func: "main.foo", Line: 7, Path: "/src/a.go"},
+			{Func: "main.foo", Line: 7, Path: "/src/a.go"},
+			{Func: "github.com/john/doe.Bar", Line: 1234, Path: "/src/github.com/john/doe/bar.go"},
+			{Func: "main.main", Line: 42, Path: "/src/main.go"},
+		},
+	)
+	output := tracerr.SprintWithOptions(err, tracerr.WithAlignedHeaders(true), tracerr.WithCollapseRepeats(true))
+	expected := strings.Join([]string{
+		"some error",
+		"/src/a.go:7                          main.foo() (repeated 2 times)",
+		"/src/github.com/john/doe/bar.go:1234 github.com/john/doe.Bar()",
+		"/src/main.go:42                      main.main()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithAlignedHeaders(true), tracerr.WithSource(1))
+	expected = strings.Join([]string{
+		"some error",
+		"",
+		"/src/a.go:7                          main.foo()",
+		"tracerr: file /src/a.go not found",
+		"",
+		"/src/a.go:7                          main.foo()",
+		"tracerr: file /src/a.go not found",
+		"",
+		"/src/github.com/john/doe/bar.go:1234 github.com/john/doe.Bar()",
+		"tracerr: file /src/github.com/john/doe/bar.go not found",
+		"",
+		"/src/main.go:42                      main.main()",
+		"tracerr: file /src/main.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithAlignedHeaders(false))
+	if !strings.Contains(output, "\n/src/a.go:7 main.foo()\n") {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want unaligned headers", output)
+	}
+}
+
+func TestWithMergeInlined(t *testing.T) {
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{Func: "main.inlined", Line: 10, Path: "/src/main.go"},
+			{Func: "main.outer", Line: 10, Path: "/src/main.go"},
+			{Func: "main.recurse", Line: 20, Path: "/src/main.go"},
+			{Func: "main.recurse", Line: 20, Path: "/src/main.go"},
+			{Func: "main.main", Line: 30, Path: "/src/main.go"},
+		},
+	)
+	output := tracerr.SprintWithOptions(err, tracerr.WithMergeInlined(true))
+	expected := strings.Join([]string{
+		"some error",
+		"/src/main.go:10 main.outer() → main.inlined()",
+		"/src/main.go:20 main.recurse()",
+		"/src/main.go:20 main.recurse()",
+		"/src/main.go:30 main.main()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithMergeInlined(false))
+	if !strings.Contains(output, "\n/src/main.go:10 main.inlined()\n/src/main.go:10 main.outer()\n") {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want frames not merged", output)
+	}
+
+	// Formatter gets merged frame with its own function.
+	defer tracerr.SetFrameFormatter(nil)
+	var funcs []string
+	tracerr.SetFrameFormatter(func(frame tracerr.Frame) string {
+		funcs = append(funcs, frame.Func)
+		return frame.Func
+	})
+	output = tracerr.SprintWithOptions(err, tracerr.WithMergeInlined(true))
+	expectedFuncs := []string{"main.inlined", "main.recurse", "main.recurse", "main.main"}
+	if !reflect.DeepEqual(funcs, expectedFuncs) {
+		t.Errorf("funcs = %#v; want %#v", funcs, expectedFuncs)
+	}
+	if !strings.HasPrefix(output, "some error\nmain.inlined\nmain.recurse\n") {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want formatted frames", output)
+	}
+}
+
+func TestWithRuntimeInfo(t *testing.T) {
+	err := tracerr.CustomError(errors.New("some error"), []tracerr.Frame{
+		{Func: "main.foo", Line: 42, Path: "/src/main.go"},
+	})
+	info := runtime.Version() + " " + runtime.GOOS + "/" + runtime.GOARCH
+	output := tracerr.SprintWithOptions(err, tracerr.WithRuntimeInfo(true))
+	expected := strings.Join([]string{
+		"some error",
+		info,
+		"/src/main.go:42 main.foo()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+	for _, output = range []string{tracerr.Sprint(err), tracerr.SprintWithOptions(err, tracerr.WithRuntimeInfo(false))} {
+		if strings.Contains(output, info) {
+			t.Errorf("output = %#v; want no runtime info", output)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package tracerr
+
+// Attribute is a key-value pair in a shape of OpenTelemetry attribute,
+// so it can be mapped to attribute.KeyValue with no dependency on OpenTelemetry SDK.
+// Value is one of string or int.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// SpanAttributes returns attributes of err to record on a span:
+//
+//	exception.message    - error message
+//	exception.stacktrace - the same output as Sprint
+//	tracerr.frames       - number of frames
+//	code.function        - func of the frame where error was created
+//	code.filepath        - path of the frame where error was created
+//	code.lineno          - line of the frame where error was created
+//
+// Frame attributes are omitted if err has no stack trace.
+// It returns nil if err is nil.
+func SpanAttributes(err error) []Attribute {
+	if err == nil {
+		return nil
+	}
+	attrs := []Attribute{
+		{Key: "exception.message", Value: err.Error()},
+	}
+	frames := StackTrace(err)
+	if len(frames) == 0 {
+		return attrs
+	}
+	top := frames[0]
+	return append(attrs,
+		Attribute{Key: "exception.stacktrace", Value: Sprint(err)},
+		Attribute{Key: "tracerr.frames", Value: len(frames)},
+		Attribute{Key: "code.function", Value: top.Func},
+		Attribute{Key: "code.filepath", Value: top.Path},
+		Attribute{Key: "code.lineno", Value: top.Line},
+	)
+}
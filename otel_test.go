@@ -0,0 +1,43 @@
+package tracerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestSpanAttributes(t *testing.T) {
+	err := tracerr.CustomError(errors.New("span error"), []tracerr.Frame{
+		{Func: "main.foo", Line: 42, Path: "/src/foo.go"},
+		{Func: "main.main", Line: 7, Path: "/src/main.go"},
+	})
+	attrs := tracerr.SpanAttributes(err)
+	expected := []tracerr.Attribute{
+		{Key: "exception.message", Value: "span error"},
+		{Key: "exception.stacktrace", Value: "span error\n/src/foo.go:42 main.foo()\n/src/main.go:7 main.main()"},
+		{Key: "tracerr.frames", Value: 2},
+		{Key: "code.function", Value: "main.foo"},
+		{Key: "code.filepath", Value: "/src/foo.go"},
+		{Key: "code.lineno", Value: 42},
+	}
+	if len(attrs) != len(expected) {
+		t.Fatalf("len(attrs) = %#v; want %#v", len(attrs), len(expected))
+	}
+	for i, attr := range attrs {
+		if attr != expected[i] {
+			t.Errorf("attrs[%#v] = %#v; want %#v", i, attr, expected[i])
+		}
+	}
+}
+
+func TestSpanAttributesNoStackTrace(t *testing.T) {
+	if attrs := tracerr.SpanAttributes(nil); attrs != nil {
+		t.Errorf("tracerr.SpanAttributes(nil) = %#v; want nil", attrs)
+	}
+	attrs := tracerr.SpanAttributes(errors.New("plain"))
+	expected := tracerr.Attribute{Key: "exception.message", Value: "plain"}
+	if len(attrs) != 1 || attrs[0] != expected {
+		t.Errorf("tracerr.SpanAttributes(plain) = %#v; want %#v", attrs, []tracerr.Attribute{expected})
+	}
+}
@@ -0,0 +1,143 @@
+package tracerr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// PathMode defines how frame paths are displayed.
+type PathMode int
+
+const (
+	// PathAbsolute displays paths as they are, with prefix set by SetTrimPath trimmed.
+	PathAbsolute PathMode = iota
+	// PathRelative displays paths relative to working directory.
+	PathRelative
+	// PathShort displays only file names, such as main.go.
+	PathShort
+)
+
+var pathMutex sync.RWMutex
+
+var trimPath string
+
+var pathMode = PathAbsolute
+
+// workDir is a working directory at the moment PathRelative mode was set.
+var workDir string
+
+var pathRedactor func(string) string
+
+var slashPaths bool
+
+// SetTrimPath sets a directory prefix to trim from displayed frame paths,
+// such as a project root, so paths are shown relative to it.
+// Paths outside the prefix are left untouched.
+// Pass an empty string to display paths as is.
+//
+// It affects only output, frame paths stay the same.
+func SetTrimPath(prefix string) {
+	pathMutex.Lock()
+	defer pathMutex.Unlock()
+	trimPath = strings.TrimRight(prefix, `/\`)
+}
+
+// SetPathMode sets how frame paths are displayed in output,
+// PathAbsolute is used by default.
+// For PathRelative mode, working directory is read once, when mode is set.
+//
+// It affects only output, frame paths stay the same.
+func SetPathMode(mode PathMode) {
+	dir := ""
+	if mode == PathRelative {
+		dir, _ = os.Getwd()
+	}
+	pathMutex.Lock()
+	defer pathMutex.Unlock()
+	pathMode = mode
+	workDir = dir
+}
+
+// SetPathRedactor sets a function, which removes sensitive parts of paths,
+// such as RedactHomeDir, so output could be shown to end users.
+// It's applied to displayed paths after SetTrimPath and SetPathMode.
+// Pass nil to display paths with no redaction, which is a default.
+//
+// It affects only output, frame paths stay the same,
+// so do structured formats, such as JSON.
+func SetPathRedactor(redactor func(string) string) {
+	pathMutex.Lock()
+	defer pathMutex.Unlock()
+	pathRedactor = redactor
+}
+
+// SetSlashPaths defines whether backslashes in displayed paths are replaced with forward slashes,
+// such as C:/src/main.go instead of C:\src\main.go, so output is the same on all platforms.
+// It's applied after SetTrimPath and SetPathMode, but before SetPathRedactor.
+//
+// It affects only output, frame paths stay the same, so source files are read by original paths.
+func SetSlashPaths(enabled bool) {
+	pathMutex.Lock()
+	defer pathMutex.Unlock()
+	slashPaths = enabled
+}
+
+// RedactHomeDir replaces home directory prefix of path,
+// such as /home/john/ or /Users/john/, with ~/,
+// so path doesn't contain a user name.
+func RedactHomeDir(path string) string {
+	for _, home := range []string{"/home/", "/Users/"} {
+		if !strings.HasPrefix(path, home) {
+			continue
+		}
+		user, rest, ok := strings.Cut(path[len(home):], "/")
+		if ok && user != "" {
+			return "~/" + rest
+		}
+	}
+	return path
+}
+
+// displayPath returns path as it is shown in output.
+func displayPath(path string) string {
+	pathMutex.RLock()
+	redactor := pathRedactor
+	slash := slashPaths
+	pathMutex.RUnlock()
+	path = shownPath(path)
+	if slash {
+		path = strings.ReplaceAll(path, `\`, "/")
+	}
+	if redactor != nil {
+		path = redactor(path)
+	}
+	return path
+}
+
+// shownPath returns path with prefix trimmed according to path mode.
+func shownPath(path string) string {
+	pathMutex.RLock()
+	prefix := trimPath
+	mode := pathMode
+	dir := workDir
+	pathMutex.RUnlock()
+	switch mode {
+	case PathShort:
+		return filepath.Base(path)
+	case PathRelative:
+		if dir != "" && filepath.IsAbs(path) {
+			if rel, err := filepath.Rel(dir, path); err == nil {
+				return rel
+			}
+		}
+		return path
+	}
+	if prefix != "" && len(path) > len(prefix) && strings.HasPrefix(path, prefix) {
+		if rest := path[len(prefix):]; rest[0] == '/' || rest[0] == '\\' {
+			path = rest[1:]
+		}
+	}
+	return path
+}
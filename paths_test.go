@@ -0,0 +1,301 @@
+package tracerr_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ztrue/tracerr"
+)
+
+type TrimPathTestCase struct {
+	Prefix   string
+	Path     string
+	Expected string
+}
+
+func TestSetTrimPath(t *testing.T) {
+	defer tracerr.SetTrimPath("")
+	cases := []TrimPathTestCase{
+		{
+			Prefix:   "",
+			Path:     "/src/github.com/john/doe/foobar.go",
+			Expected: "/src/github.com/john/doe/foobar.go:42 main.foo()",
+		},
+		{
+			Prefix:   "/src/github.com/john",
+			Path:     "/src/github.com/john/doe/foobar.go",
+			Expected: "doe/foobar.go:42 main.foo()",
+		},
+		{
+			Prefix:   "/src/github.com/john/",
+			Path:     "/src/github.com/john/doe/foobar.go",
+			Expected: "doe/foobar.go:42 main.foo()",
+		},
+		{
+			Prefix:   "/src/github.com/jo",
+			Path:     "/src/github.com/john/doe/foobar.go",
+			Expected: "/src/github.com/john/doe/foobar.go:42 main.foo()",
+		},
+		{
+			Prefix:   "/src/github.com/jane",
+			Path:     "/src/github.com/john/doe/foobar.go",
+			Expected: "/src/github.com/john/doe/foobar.go:42 main.foo()",
+		},
+	}
+
+	for i, c := range cases {
+		tracerr.SetTrimPath(c.Prefix)
+		frame := tracerr.Frame{
+			Func: "main.foo",
+			Line: 42,
+			Path: c.Path,
+		}
+		if frame.String() != c.Expected {
+			t.Errorf(
+				"case #%d: frame.String() = %#v; want %#v",
+				i, frame.String(), c.Expected,
+			)
+		}
+	}
+}
+
+func TestSetTrimPathSource(t *testing.T) {
+	defer tracerr.SetTrimPath("")
+	tracerr.SetTrimPath("/tmp")
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.Foo",
+				Line: 42,
+				Path: "/tmp/not_exists.go",
+			},
+		},
+	)
+	output := tracerr.SprintSource(err)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"not_exists.go:42 main.Foo()",
+		"tracerr: file not_exists.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf(
+			"tracerr.SprintSource(err) = %#v; want %#v",
+			output, expected,
+		)
+	}
+}
+
+type PathModeTestCase struct {
+	Mode     tracerr.PathMode
+	Path     string
+	Expected string
+}
+
+func TestSetPathMode(t *testing.T) {
+	defer tracerr.SetPathMode(tracerr.PathAbsolute)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inside := filepath.Join(wd, "internal", "foobar.go")
+	cases := []PathModeTestCase{
+		{
+			Mode:     tracerr.PathAbsolute,
+			Path:     inside,
+			Expected: inside,
+		},
+		{
+			Mode:     tracerr.PathRelative,
+			Path:     inside,
+			Expected: filepath.Join("internal", "foobar.go"),
+		},
+		{
+			Mode:     tracerr.PathRelative,
+			Path:     filepath.Join(wd, "..", "other", "foobar.go"),
+			Expected: filepath.Join("..", "other", "foobar.go"),
+		},
+		{
+			Mode:     tracerr.PathRelative,
+			Path:     "relative/foobar.go",
+			Expected: "relative/foobar.go",
+		},
+		{
+			Mode:     tracerr.PathShort,
+			Path:     inside,
+			Expected: "foobar.go",
+		},
+	}
+
+	for i, c := range cases {
+		tracerr.SetPathMode(c.Mode)
+		frame := tracerr.Frame{
+			Func: "main.foo",
+			Line: 42,
+			Path: c.Path,
+		}
+		expected := c.Expected + ":42 main.foo()"
+		if s := frame.String(); s != expected {
+			t.Errorf("cases[%#v].String() = %#v; want %#v", i, s, expected)
+		}
+		if frame.Path != c.Path {
+			t.Errorf("cases[%#v].Path = %#v; want %#v", i, frame.Path, c.Path)
+		}
+	}
+}
+
+func TestSetPathModeSource(t *testing.T) {
+	defer tracerr.SetPathMode(tracerr.PathAbsolute)
+	tracerr.SetPathMode(tracerr.PathShort)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.Foo",
+				Line: 42,
+				Path: "/tmp/not_exists.go",
+			},
+		},
+	)
+	output := tracerr.SprintSource(err)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"not_exists.go:42 main.Foo()",
+		"tracerr: file not_exists.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf(
+			"tracerr.SprintSource(err) = %#v; want %#v",
+			output, expected,
+		)
+	}
+}
+
+func TestSetPathRedactor(t *testing.T) {
+	defer tracerr.SetPathRedactor(nil)
+	defer tracerr.SetTrimPath("")
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.foo",
+				Line: 42,
+				Path: "/home/john/project/main.go",
+			},
+			{
+				Func: "main.main",
+				Line: 10,
+				Path: "/Users/jane/go/src/main.go",
+			},
+			{
+				Func: "main.bar",
+				Line: 1,
+				Path: "/src/bar.go",
+			},
+		},
+	)
+
+	tracerr.SetPathRedactor(tracerr.RedactHomeDir)
+	output := tracerr.SprintSource(err, 0, 0)
+	for _, user := range []string{"john", "jane"} {
+		if strings.Contains(output, user) {
+			t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want no %#v", output, user)
+		}
+	}
+	expected := []string{
+		"~/project/main.go:42 main.foo()",
+		"tracerr: file ~/project/main.go not found",
+		"~/go/src/main.go:10 main.main()",
+		"/src/bar.go:1 main.bar()",
+	}
+	for _, row := range expected {
+		if !strings.Contains(output, row+"\n") && !strings.HasSuffix(output, row) {
+			t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want row %#v", output, row)
+		}
+	}
+
+	// Redactor runs after trim.
+	tracerr.SetTrimPath("/home/john/project")
+	tracerr.SetPathRedactor(func(path string) string {
+		return "[" + path + "]"
+	})
+	frame := tracerr.StackTrace(err)[0]
+	if frame.String() != "[main.go]:42 main.foo()" {
+		t.Errorf("frame.String() = %#v; want %#v", frame.String(), "[main.go]:42 main.foo()")
+	}
+	if frame.Path != "/home/john/project/main.go" {
+		t.Errorf("frame.Path = %#v; want unchanged", frame.Path)
+	}
+
+	tracerr.SetPathRedactor(nil)
+	tracerr.SetTrimPath("")
+	if frame.String() != "/home/john/project/main.go:42 main.foo()" {
+		t.Errorf("frame.String() = %#v; want %#v", frame.String(), "/home/john/project/main.go:42 main.foo()")
+	}
+}
+
+func TestRedactHomeDir(t *testing.T) {
+	cases := map[string]string{
+		"/home/john/project/main.go": "~/project/main.go",
+		"/Users/jane/main.go":        "~/main.go",
+		"/home/john":                 "/home/john",
+		"/home//main.go":             "/home//main.go",
+		"/src/home/john/main.go":     "/src/home/john/main.go",
+		"main.go":                    "main.go",
+	}
+	for path, expected := range cases {
+		if redacted := tracerr.RedactHomeDir(path); redacted != expected {
+			t.Errorf("tracerr.RedactHomeDir(%#v) = %#v; want %#v", path, redacted, expected)
+		}
+	}
+}
+
+func TestSetSlashPaths(t *testing.T) {
+	defer tracerr.SetSlashPaths(false)
+	defer tracerr.SetSourceFS(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		`src\main.go`: {Data: []byte("package main")},
+	})
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.main",
+				Line: 1,
+				Path: `src\main.go`,
+			},
+			{
+				Func: "main.foo",
+				Line: 1,
+				Path: `C:\src\foo.go`,
+			},
+		},
+	)
+
+	tracerr.SetSlashPaths(true)
+	output := tracerr.SprintSource(err, 0, 0)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"src/main.go:1 main.main()",
+		"1\tpackage main",
+		"",
+		"C:/src/foo.go:1 main.foo()",
+		"tracerr: file C:/src/foo.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetSlashPaths(false)
+	output = tracerr.Sprint(err)
+	if !strings.Contains(output, `C:\src\foo.go:1 main.foo()`) {
+		t.Errorf("tracerr.Sprint(err) = %#v; want original separators", output)
+	}
+}
@@ -0,0 +1,50 @@
+package tracerr_test
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func capturePCs() ([]uintptr, tracerr.Error) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(1, pcs)
+	err := tracerr.New("native error")
+	return pcs[:n], err
+}
+
+func TestFromPCs(t *testing.T) {
+	pcs, native := capturePCs()
+	err := tracerr.FromPCs("pcs error", pcs)
+	if err.Error() != "pcs error" {
+		t.Errorf("err.Error() = %#v; want %#v", err.Error(), "pcs error")
+	}
+	frames := err.StackTrace()
+	nativeFrames := native.StackTrace()
+	if len(frames) != len(nativeFrames) {
+		t.Fatalf("len(frames) = %#v; want %#v", len(frames), len(nativeFrames))
+	}
+	caller := tracerr.Frame{
+		Func: "github.com/ztrue/tracerr_test.capturePCs",
+		Line: 13,
+		Path: nativeFrames[0].Path,
+	}
+	if frames[0] != caller {
+		t.Errorf("frames[0] = %#v; want %#v", frames[0], caller)
+	}
+	if !reflect.DeepEqual(frames[1:], nativeFrames[1:]) {
+		t.Errorf("frames[1:] = %#v; want %#v", frames[1:], nativeFrames[1:])
+	}
+
+	copied := tracerr.FromPCs("pcs error", pcs)
+	pcs[0] = 0
+	if frame := copied.StackTrace()[0]; frame != caller {
+		t.Errorf("copied.StackTrace()[0] after changing pcs = %#v; want %#v", frame, caller)
+	}
+
+	if frames := tracerr.FromPCs("no pcs", nil).StackTrace(); len(frames) != 0 {
+		t.Errorf("tracerr.FromPCs(..., nil).StackTrace() = %#v; want no frames", frames)
+	}
+}
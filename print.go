@@ -1,29 +1,253 @@
 package tracerr
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
 )
 
 // DefaultLinesAfter is number of source lines after traced line to display.
+//
+// Deprecated: Use SetDefaultLines, which is safe for concurrent use.
+// Assigning the variable while other goroutines print errors is a data race.
 var DefaultLinesAfter = 2
 
 // DefaultLinesBefore is number of source lines before traced line to display.
+//
+// Deprecated: Use SetDefaultLines, which is safe for concurrent use.
+// Assigning the variable while other goroutines print errors is a data race.
 var DefaultLinesBefore = 3
 
-var cache = map[string][]string{}
+var tabWidth atomic.Int64
+
+// SetTabWidth sets a number of spaces to replace each tab with in source fragments,
+// including a tab between line number and source code.
+// If n <= 0 tabs are kept as is, which is a default.
+func SetTabWidth(n int) {
+	tabWidth.Store(int64(n))
+}
+
+var maxSourceLineWidth atomic.Int64
+
+// SetMaxSourceLineWidth sets a maximum width in columns of source lines
+// in source fragments, such as for minified or generated code.
+// Longer lines are truncated with an ellipsis.
+// Tabs are counted after replacement defined by SetTabWidth, or up to the next tab stop of 8 columns,
+// East Asian wide characters take 2 columns and combining marks take none.
+// If n <= 0 lines are not truncated, which is a default.
+// To fit source rows into terminal width, see WithTerminalWidth.
+func SetMaxSourceLineWidth(n int) {
+	maxSourceLineWidth.Store(int64(n))
+}
+
+// truncateLine truncates line to n columns, including an ellipsis,
+// tab is the same as in caretRow.
+func truncateLine(line string, n int, tab string) string {
+	if n <= 0 || textColumns(line, tab) <= n {
+		return line
+	}
+	col := 0
+	for i, r := range line {
+		w := runeColumns(r, col, tab)
+		if col+w > n-1 {
+			return line[:i] + "…"
+		}
+		col += w
+	}
+	return line
+}
+
+var maxOutputBytes atomic.Int64
+
+// truncatedMarker is the last line of output truncated by SetMaxOutputBytes.
+const truncatedMarker = "... (output truncated)"
+
+// SetMaxOutputBytes sets a maximum size of output in bytes,
+// such as for log systems which truncate long lines.
+// Output over the limit is cut by whole lines and followed by "... (output truncated)" line,
+// so colorized output is never cut inside a color sequence.
+// If the limit is shorter than the marker, output consists of the marker only.
+// 0 means no limit, which is a default.
+func SetMaxOutputBytes(n int) {
+	maxOutputBytes.Store(int64(n))
+}
+
+// limitOutput cuts output to n bytes including truncatedMarker, if it's longer.
+func limitOutput(output string, n int) string {
+	if n <= 0 || len(output) <= n {
+		return output
+	}
+	budget := n - len(truncatedMarker)
+	size := 0
+	rows := strings.Split(output, "\n")
+	for i, row := range rows {
+		if size+len(row)+1 > budget {
+			kept := strings.Join(rows[:i], "\n")
+			if i > 0 {
+				kept += "\n"
+			}
+			return kept + truncatedMarker
+		}
+		size += len(row) + 1
+	}
+	return output
+}
+
+var formatterMutex sync.RWMutex
 
-var mutex sync.RWMutex
+var sourceLineFormatter func(lineNum int, text string, isTraced bool, colorized bool) string
 
-// Print prints error message with stack trace.
+// SetSourceLineFormatter sets a function, which formats each line of source fragments
+// instead of the default "number<tab>text" format.
+// It gets line number, line text, whether it's a traced line and whether output is colorized.
+// Pass nil to restore the default format.
+func SetSourceLineFormatter(formatter func(lineNum int, text string, isTraced bool, colorized bool) string) {
+	formatterMutex.Lock()
+	defer formatterMutex.Unlock()
+	sourceLineFormatter = formatter
+}
+
+func getSourceLineFormatter() func(int, string, bool, bool) string {
+	formatterMutex.RLock()
+	defer formatterMutex.RUnlock()
+	return sourceLineFormatter
+}
+
+var frameFormatter func(Frame) string
+
+// SetFrameFormatter sets a function, which formats header line of each frame
+// instead of Frame.String, such as FormatFileLine or FormatFuncFileLine.
+// Pass nil to restore the default format.
+func SetFrameFormatter(formatter func(Frame) string) {
+	formatterMutex.Lock()
+	defer formatterMutex.Unlock()
+	frameFormatter = formatter
+}
+
+// getFrameFormatter returns formatter set by SetFrameFormatter.
+func getFrameFormatter() func(Frame) string {
+	formatterMutex.RLock()
+	defer formatterMutex.RUnlock()
+	return frameFormatter
+}
+
+// formatFrame formats frame header with formatter set by SetFrameFormatter.
+func formatFrame(frame Frame) string {
+	formatter := getFrameFormatter()
+	if formatter == nil {
+		return frame.String()
+	}
+	return formatter(frame)
+}
+
+// FormatFileLine formats frame as "path:line".
+func FormatFileLine(frame Frame) string {
+	return fmt.Sprintf("%s:%d", displayPath(frame.Path), frame.Line)
+}
+
+// FormatFuncFileLine formats frame as "path:line func".
+func FormatFuncFileLine(frame Frame) string {
+	return fmt.Sprintf("%s:%d %s", displayPath(frame.Path), frame.Line, frame.Func)
+}
+
+var sourceErrorHandler func(frame Frame, err error) string
+
+// SetSourceErrorHandler sets a function, which returns a message displayed
+// instead of source fragment, if source is not available,
+// such as when file is not found or has too few lines.
+// It could localize or reformat messages, or return an empty string to hide them.
+// Pass nil to restore default messages.
+func SetSourceErrorHandler(handler func(frame Frame, err error) string) {
+	formatterMutex.Lock()
+	defer formatterMutex.Unlock()
+	sourceErrorHandler = handler
+}
+
+// sourceErrorMessage returns message displayed if source of frame is not available.
+func sourceErrorMessage(frame Frame, err error) string {
+	formatterMutex.RLock()
+	handler := sourceErrorHandler
+	formatterMutex.RUnlock()
+	if handler == nil {
+		return err.Error()
+	}
+	return handler(frame, err)
+}
+
+var writerMutex sync.RWMutex
+
+// defaultWriter is nil for os.Stdout, which is resolved on each call.
+var defaultWriter io.Writer
+
+// SetDefaultWriter sets a writer used by Print, PrintSource and PrintSourceColor,
+// such as os.Stderr, unless writer is set for them by SetPrintWriter.
+// Pass nil to restore os.Stdout, which is a default.
+//
+// Fprint, FprintSource and FprintSourceColor write to their own writer.
+func SetDefaultWriter(w io.Writer) {
+	writerMutex.Lock()
+	defer writerMutex.Unlock()
+	defaultWriter = w
+}
+
+// PrintVariant is one of Print functions, which writer is set by SetPrintWriter.
+type PrintVariant int
+
+const (
+	// VariantPrint is Print.
+	VariantPrint PrintVariant = iota
+	// VariantPrintSource is PrintSource.
+	VariantPrintSource
+	// VariantPrintSourceColor is PrintSourceColor.
+	VariantPrintSourceColor
+)
+
+// printWriters contains writers set by SetPrintWriter.
+var printWriters = map[PrintVariant]io.Writer{}
+
+// SetPrintWriter sets a writer used by one of Print functions instead of default writer,
+// such as os.Stderr for short output of Print and a log file for verbose PrintSource.
+// Pass nil to restore default writer, see SetDefaultWriter.
+func SetPrintWriter(variant PrintVariant, w io.Writer) {
+	writerMutex.Lock()
+	defer writerMutex.Unlock()
+	if w == nil {
+		delete(printWriters, variant)
+		return
+	}
+	printWriters[variant] = w
+}
+
+func getWriter(variant PrintVariant) io.Writer {
+	writerMutex.RLock()
+	w, ok := printWriters[variant]
+	if !ok {
+		w = defaultWriter
+	}
+	writerMutex.RUnlock()
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
+// Print prints error message with stack trace to default writer,
+// which is os.Stdout unless changed by SetDefaultWriter or SetPrintWriter.
 func Print(err error) {
-	fmt.Println(Sprint(err))
+	Fprint(getWriter(VariantPrint), err)
 }
 
-// PrintSource prints error message with stack trace and source fragments.
+// PrintSource prints error message with stack trace and source fragments
+// to default writer, the same as Print.
 //
 // By default, 6 lines of source code will be printed,
 // see DefaultLinesAfter and DefaultLinesBefore.
@@ -33,34 +257,76 @@ func Print(err error) {
 // Pass two numbers to specify exactly how many lines should be shown
 // before and after traced line.
 func PrintSource(err error, nums ...int) {
-	fmt.Println(SprintSource(err, nums...))
+	FprintSource(getWriter(VariantPrintSource), err, nums...)
 }
 
 // PrintSourceColor prints error message with stack trace and source fragments,
-// which are in color.
+// which are in color, to default writer, the same as Print.
 // Output rules are the same as in PrintSource.
+//
+// In ColorAuto mode colors are used only if the writer is a terminal, see SetColorMode.
 func PrintSourceColor(err error, nums ...int) {
-	fmt.Println(SprintSourceColor(err, nums...))
+	FprintSourceColor(getWriter(VariantPrintSourceColor), err, nums...)
+}
+
+// Fprint writes error message with stack trace to w.
+// Output rules are the same as in Print.
+func Fprint(w io.Writer, err error) {
+	fmt.Fprintln(w, Sprint(err))
+}
+
+// FprintSource writes error message with stack trace and source fragments to w.
+// Output rules are the same as in PrintSource.
+func FprintSource(w io.Writer, err error, nums ...int) {
+	fmt.Fprintln(w, SprintSource(err, nums...))
+}
+
+// FprintSourceColor writes error message with stack trace and source fragments,
+// which are in color, to w.
+// Output rules are the same as in PrintSourceColor.
+//
+// In ColorAuto mode colors are used only if w is a terminal, see SetColorMode.
+func FprintSourceColor(w io.Writer, err error, nums ...int) {
+	fmt.Fprintln(w, SprintWithOptions(
+		err,
+		WithSource(nums...),
+		WithColor(isColorEnabledFor(w)),
+	))
 }
 
 // Sprint returns error output by the same rules as Print.
 func Sprint(err error) string {
-	return sprint(err, []int{0}, false)
+	return SprintWithOptions(err)
 }
 
 // SprintSource returns error output by the same rules as PrintSource.
 func SprintSource(err error, nums ...int) string {
-	return sprint(err, nums, false)
+	return SprintWithOptions(err, WithSource(nums...))
 }
 
 // SprintSourceColor returns error output by the same rules as PrintSourceColor.
 func SprintSourceColor(err error, nums ...int) string {
-	return sprint(err, nums, true)
+	return SprintWithOptions(err, WithSource(nums...), WithColor(true))
+}
+
+// SprintCaller returns error message and only the innermost frame,
+// where error was created, with source fragment.
+// nums are the same as in PrintSource.
+func SprintCaller(err error, nums ...int) string {
+	return SprintWithOptions(err, WithSource(nums...), WithMaxFrames(1))
+}
+
+// SprintWithOptions returns error output configured by opts.
+// With no options output is the same as in Sprint.
+//
+// Options are applied to this call only, so it's safe to use
+// different options concurrently.
+func SprintWithOptions(err error, opts ...Option) string {
+	return limitOutput(sprint(err, newOptions(opts)), int(maxOutputBytes.Load()))
 }
 
 func calcRows(nums []int) (before, after int, withSource bool) {
-	before = DefaultLinesBefore
-	after = DefaultLinesAfter
+	before, after = defaultLines()
 	withSource = true
 	if len(nums) > 1 {
 		before = nums[0]
@@ -87,96 +353,376 @@ func calcRows(nums []int) (before, after int, withSource bool) {
 }
 
 func readLines(path string) ([]string, error) {
-	mutex.RLock()
-	lines, ok := cache[path]
-	mutex.RUnlock()
+	lines, ok := cache.get(path)
 	if ok {
 		return lines, nil
 	}
 
-	b, err := os.ReadFile(path)
+	b, err := readSource(path)
+	if errors.Is(err, errFileTooLarge) {
+		return nil, fmt.Errorf("tracerr: file %s is too large, max %d bytes", displayPath(path), maxSourceFileBytes.Load())
+	}
 	if err != nil {
-		return nil, fmt.Errorf("tracerr: file %s not found", path)
+		return nil, fmt.Errorf("tracerr: file %s not found", displayPath(path))
 	}
+	// Lines keep CR of CRLF line endings, so byte offsets could be counted.
 	lines = strings.Split(string(b), "\n")
-	mutex.Lock()
-	defer mutex.Unlock()
-	cache[path] = lines
+	cache.set(path, lines)
 	return lines, nil
 }
 
-func sourceRows(rows []string, frame Frame, before, after int, colorized bool) []string {
+// sourceLine is a line of source fragment.
+type sourceLine struct {
+	// Number contains a line number.
+	Number int
+	// Text contains a line of source code.
+	Text string
+	// Traced is true for a traced line or a line in traced range.
+	Traced bool
+}
+
+// sourceWindow returns source lines around traced line,
+// or around traced range of lines if frame has EndLine.
+// Error message describes why source is not available.
+func sourceWindow(frame Frame, before, after int) ([]sourceLine, error) {
 	lines, err := readLines(frame.Path)
 	if err != nil {
-		message := err.Error()
-		if colorized {
-			message = yellow(message)
-		}
-		return append(rows, message, "")
+		return nil, err
 	}
 	if len(lines) < frame.Line {
-		message := fmt.Sprintf(
+		return nil, fmt.Errorf(
 			"tracerr: too few lines, got %d, want %d",
 			len(lines), frame.Line,
 		)
-		if colorized {
-			message = yellow(message)
-		}
-		return append(rows, message, "")
 	}
 	current := frame.Line - 1
+	last := current
+	if frame.EndLine > frame.Line {
+		last = frame.EndLine - 1
+	}
+	if last >= len(lines) {
+		last = len(lines) - 1
+	}
 	start := current - before
-	end := current + after
+	if start < 0 {
+		start = 0
+	}
+	end := last + after
+	if end >= len(lines) {
+		end = len(lines) - 1
+	}
+	window := make([]sourceLine, 0, end-start+1)
 	for i := start; i <= end; i++ {
-		if i < 0 || i >= len(lines) {
-			continue
+		// Files with CRLF line endings are displayed the same way as with LF.
+		window = append(window, sourceLine{
+			Number: i + 1,
+			Text:   strings.TrimSuffix(lines[i], "\r"),
+			Traced: i >= current && i <= last,
+		})
+	}
+	return window, nil
+}
+
+func sourceRows(rows []string, frame Frame, before, after int, o *options) []string {
+	window, err := sourceWindow(frame, before, after)
+	if err != nil {
+		message := sourceErrorMessage(frame, err)
+		if message == "" {
+			return rows
+		}
+		if o.colorized {
+			message = warningColor(message)
+		}
+		return append(rows, message)
+	}
+	return windowRows(rows, []Frame{frame}, window, o)
+}
+
+// windowRows returns rows of source window of frames in the same file,
+// lines skipped in window are replaced with a single "..." row.
+func windowRows(rows []string, frames []Frame, window []sourceLine, o *options) []string {
+	if len(window) == 0 {
+		return rows
+	}
+	path := frames[0].Path
+	colorized := o.colorized
+	highlighted := colorized && o.syntaxHighlight && strings.HasSuffix(path, ".go")
+	if o.staleWarning && sourceModified(path) {
+		message := fmt.Sprintf("tracerr: file %s is modified after binary was built, source may not match", displayPath(path))
+		if colorized {
+			message = warningColor(message)
+		}
+		rows = append(rows, message)
+	}
+	if sourceHashMismatch(path) {
+		message := fmt.Sprintf("tracerr: file %s does not match hash recorded at build time, source may not match", displayPath(path))
+		if colorized {
+			message = warningColor(message)
+		}
+		rows = append(rows, message)
+	}
+	indent := ""
+	if o.dedentSource {
+		indent = dedent(window)
+	}
+	// Line numbers are padded to the same length.
+	width := len(strconv.Itoa(window[len(window)-1].Number))
+	tab := "\t"
+	if n := int(tabWidth.Load()); n > 0 {
+		tab = strings.Repeat(" ", n)
+	}
+	maxWidth := int(maxSourceLineWidth.Load())
+	if o.fitTerminal {
+		if fit := sourceTextWidth(TerminalWidth(), width, tab); maxWidth <= 0 || fit < maxWidth {
+			maxWidth = fit
+		}
+	}
+	formatter := getSourceLineFormatter()
+	for i, line := range window {
+		if i > 0 && line.Number > window[i-1].Number+1 {
+			gap := "..."
+			if colorized {
+				gap = lineNumberColor(gap)
+			}
+			rows = append(rows, gap)
 		}
-		line := lines[i]
+		text := truncateLine(strings.ReplaceAll(line.Text, "\t", tab), maxWidth, tab)
 		var message string
-		// TODO Pad to the same length.
-		if i == frame.Line-1 {
-			message = fmt.Sprintf("%d\t%s", i+1, line)
+		if formatter != nil {
+			message = formatter(line.Number, text, line.Traced, colorized)
+		} else if line.Traced {
+			message = fmt.Sprintf("%*d%s%s", width, line.Number, tab, text)
 			if colorized {
-				message = red(message)
+				message = tracedLineColor(message)
 			}
 		} else if colorized {
-			message = fmt.Sprintf("%s\t%s", black(strconv.Itoa(i+1)), line)
+			if highlighted {
+				text = highlight(text)
+			}
+			message = fmt.Sprintf("%s%s%s", lineNumberColor(fmt.Sprintf("%*d", width, line.Number)), tab, text)
 		} else {
-			message = fmt.Sprintf("%d\t%s", i+1, line)
+			message = fmt.Sprintf("%*d%s%s", width, line.Number, tab, text)
 		}
 		rows = append(rows, message)
+		if formatter != nil {
+			continue
+		}
+		// Only one caret is displayed under a line, even if it's traced by multiple frames.
+		for _, frame := range frames {
+			if frame.Col > 0 && line.Number == frame.Line {
+				col := max(frame.Col-utf8.RuneCountInString(indent), 1)
+				rows = append(rows, caretRow(line.Text, col, width, tab, maxWidth, colorized))
+				break
+			}
+		}
+	}
+	return rows
+}
+
+// dedent removes the longest leading whitespace common for all non-blank lines of window,
+// so relative indentation is kept, and returns removed indentation.
+func dedent(window []sourceLine) string {
+	indent := ""
+	found := false
+	for _, line := range window {
+		if strings.TrimSpace(line.Text) == "" {
+			continue
+		}
+		lead := line.Text[:len(line.Text)-len(strings.TrimLeft(line.Text, " \t"))]
+		if !found {
+			indent = lead
+			found = true
+			continue
+		}
+		n := 0
+		for n < len(indent) && n < len(lead) && indent[n] == lead[n] {
+			n++
+		}
+		indent = indent[:n]
+	}
+	if indent == "" {
+		return ""
+	}
+	for i, line := range window {
+		if strings.TrimSpace(line.Text) == "" {
+			window[i].Text = ""
+			continue
+		}
+		window[i].Text = line.Text[len(indent):]
+	}
+	return indent
+}
+
+// filePosition returns position of traced line within its file, such as " (offset 10234)",
+// or an empty string if file could not be read.
+func filePosition(frame Frame, position Position) string {
+	lines, err := readLines(frame.Path)
+	if err != nil || frame.Line < 1 || frame.Line > len(lines) {
+		return ""
+	}
+	offset, size := 0, 0
+	for i, line := range lines {
+		if i == frame.Line-1 {
+			offset = size
+		}
+		size += len(line) + 1
+	}
+	// The last line has no line ending.
+	size--
+	if position == PositionPercent {
+		if size <= 0 {
+			return " (0%)"
+		}
+		return fmt.Sprintf(" (%d%%)", offset*100/size)
+	}
+	return fmt.Sprintf(" (offset %d)", offset)
+}
+
+// caretRow returns a row with caret under col of traced line text,
+// aligned with line number padded to width and tab.
+// If line is truncated to maxWidth and col is cut off, caret is under the ellipsis.
+func caretRow(text string, col, width int, tab string, maxWidth int, colorized bool) string {
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width))
+	b.WriteString(tab)
+	truncated := maxWidth > 0 && textColumns(strings.ReplaceAll(text, "\t", tab), tab) > maxWidth
+	pos := 0
+	for i, r := range []rune(text) {
+		if i >= col-1 {
+			break
+		}
+		w := runeColumns(r, pos, tab)
+		if truncated && pos+w > maxWidth-1 {
+			break
+		}
+		pos += w
+		if r == '\t' {
+			b.WriteString(tab)
+		} else {
+			b.WriteString(strings.Repeat(" ", w))
+		}
+	}
+	b.WriteByte('^')
+	if colorized {
+		return tracedLineColor(b.String())
 	}
-	return append(rows, "")
+	return b.String()
 }
 
-func sprint(err error, nums []int, colorized bool) string {
+func sprint(err error, o *options) string {
 	if err == nil {
 		return ""
 	}
+	if joined, ok := err.(*joinError); ok {
+		return sprintJoin(joined, o)
+	}
+	if grouped, ok := err.(*groupError); ok {
+		return sprintGroup(grouped, o)
+	}
 	e, ok := err.(Error)
 	if !ok {
 		return err.Error()
 	}
-	before, after, withSource := calcRows(nums)
-	frames := e.StackTrace()
+	colorized := o.colorized
+	before, after, withSource := calcRows(o.nums)
+	frames := o.ordered(o.selected(e.StackTrace()))
 	expectedRows := len(frames) + 1
 	if withSource {
-		expectedRows = (before+after+3)*len(frames) + 2
+		expectedRows = (before+after+3)*len(frames) + 1
 	}
 	rows := make([]string, 0, expectedRows)
-	rows = append(rows, e.Error())
-	if withSource {
-		rows = append(rows, "")
+	message := e.Error()
+	if kind := KindOf(e); o.withKinds && kind != "" {
+		message = "[" + string(kind) + "] " + message
 	}
-	for _, frame := range frames {
+	if colorized {
+		message = messageColor(message)
+	}
+	rows = append(rows, message)
+	if values := Values(e); o.withValues && len(values) > 0 {
+		rows = append(rows, formatValues(values))
+	}
+	if id := GoroutineID(e); id > 0 {
+		rows = append(rows, fmt.Sprintf("goroutine %d", id))
+	}
+	if ts := Timestamp(e); o.withTimestamp && !ts.IsZero() {
+		rows = append(rows, ts.Format(time.RFC3339Nano))
+	}
+	if o.runtimeInfo {
+		rows = append(rows, runtime.Version()+" "+runtime.GOOS+"/"+runtime.GOARCH)
+	}
+	var headers []string
+	if o.alignHeaders {
+		headers = alignedHeaders(frames)
+	}
+	budgets := o.sourceBudgets(len(frames), before, after)
+	// Frames with source are separated by an empty line, unless spacing is compact.
+	separated := withSource && !o.compactSpacing
+	var sources [][]string
+	var joined []bool
+	if withSource && o.mergeSource {
+		sources, joined = o.mergedSources(frames, budgets)
+	}
+	for i, frame := range frames {
+		budget := budgets[frame.index]
+		var source []string
+		if sources != nil {
+			source = sources[i]
+		} else if withSource && budget.shown && frame.hidden == 0 {
+			source = sourceRows(nil, frame.Frame, budget.before, budget.after, o)
+		}
+		// Frame with source hidden by SetSourceErrorHandler is displayed as with no source.
+		// Frames sharing a source block are not separated from each other.
+		frameSource := len(source) > 0 || (joined != nil && joined[i])
+		afterJoined := i > 0 && joined != nil && joined[i-1]
+		if (separated || frameSource) && !o.compactSpacing && !afterJoined {
+			rows = append(rows, "")
+		}
+		separated = frameSource
 		message := frame.String()
+		if headers != nil {
+			message = headers[i]
+		}
+		if o.position != PositionNone && frame.hidden == 0 {
+			message += filePosition(frame.Frame, o.position)
+		}
 		if colorized {
-			message = bold(message)
+			message = headerColor(message)
 		}
 		rows = append(rows, message)
-		if withSource {
-			rows = sourceRows(rows, frame, before, after, colorized)
-		}
+		rows = append(rows, source...)
 	}
 	return strings.Join(rows, "\n")
 }
+
+// alignedHeaders returns headers of frames with functions aligned in a column.
+// It returns nil if frame format is changed by SetFrameFormatter.
+func alignedHeaders(frames []displayFrame) []string {
+	if getFrameFormatter() != nil {
+		return nil
+	}
+	// All headers are buffered, since column width depends on the longest one.
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 1, ' ', 0)
+	for _, frame := range frames {
+		if frame.hidden > 0 {
+			continue
+		}
+		header := fmt.Sprintf("%s:%d\t%s()", displayPath(frame.Path), frame.Line, frame.funcChain())
+		if frame.repeated > 1 {
+			header += fmt.Sprintf(" (repeated %d times)", frame.repeated)
+		}
+		fmt.Fprintln(w, header)
+	}
+	_ = w.Flush()
+	aligned := strings.Split(b.String(), "\n")
+	headers := make([]string, len(frames))
+	for i, frame := range frames {
+		if frame.hidden > 0 {
+			headers[i] = frame.String()
+			continue
+		}
+		headers[i], aligned = aligned[0], aligned[1:]
+	}
+	return headers
+}
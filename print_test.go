@@ -85,10 +85,10 @@ func TestPrint(t *testing.T) {
 				"15\t",
 				"",
 				"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
-				"6\t)",
-				"7\t",
-				"8\tfunc addFrameA(message string) error {",
-				"9\t\treturn addFrameB(message)",
+				" 6\t)",
+				" 7\t",
+				" 8\tfunc addFrameA(message string) error {",
+				" 9\t\treturn addFrameB(message)",
 				"10\t}",
 				"11\t",
 				"",
@@ -124,9 +124,9 @@ func TestPrint(t *testing.T) {
 				"14\t}",
 				"",
 				"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
-				"7\t",
-				"8\tfunc addFrameA(message string) error {",
-				"9\t\treturn addFrameB(message)",
+				" 7\t",
+				" 8\tfunc addFrameA(message string) error {",
+				" 9\t\treturn addFrameB(message)",
 				"10\t}",
 				"",
 				"/tracerr/print_test.go:26 github.com/ztrue/tracerr_test.TestPrint()",
@@ -159,9 +159,9 @@ func TestPrint(t *testing.T) {
 				"14\t}",
 				"",
 				"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
-				"7\t",
-				"8\tfunc addFrameA(message string) error {",
-				"9\t\treturn addFrameB(message)",
+				" 7\t",
+				" 8\tfunc addFrameA(message string) error {",
+				" 9\t\treturn addFrameB(message)",
 				"10\t}",
 				"",
 				"/tracerr/print_test.go:26 github.com/ztrue/tracerr_test.TestPrint()",
@@ -217,7 +217,7 @@ func TestPrint(t *testing.T) {
 				"17\t\treturn tracerr.New(message)",
 				"",
 				"/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()",
-				"9\t\treturn addFrameB(message)",
+				" 9\t\treturn addFrameB(message)",
 				"10\t}",
 				"11\t",
 				"12\tfunc addFrameB(message string) error {",
@@ -251,8 +251,8 @@ func TestPrint(t *testing.T) {
 				black("14") + "\t}",
 				"",
 				bold("/tracerr/error_helper_test.go:9 github.com/ztrue/tracerr_test.addFrameA()"),
-				black("8") + "\tfunc addFrameA(message string) error {",
-				red("9\t\treturn addFrameB(message)"),
+				black(" 8") + "\tfunc addFrameA(message string) error {",
+				red(" 9\t\treturn addFrameB(message)"),
 				black("10") + "\t}",
 				"",
 				bold("/tracerr/print_test.go:26 github.com/ztrue/tracerr_test.TestPrint()"),
@@ -297,7 +297,6 @@ func TestNoLine(t *testing.T) {
 		"",
 		"error_helper_test.go:1338 main.Bar()",
 		"tracerr: too few lines, got 19, want 1338",
-		"",
 	}
 	expected := strings.Join(expectedRows, "\n")
 	if output != expected {
@@ -333,7 +332,6 @@ func TestNoLineColor(t *testing.T) {
 		"",
 		bold("error_helper_test.go:1338 main.Bar()"),
 		yellow("tracerr: too few lines, got 19, want 1338"),
-		"",
 	}
 	expected := strings.Join(expectedRows, "\n")
 	if output != expected {
@@ -369,7 +367,6 @@ func TestNoSourceFile(t *testing.T) {
 		"",
 		"/tmp/not_exists_2.go:43 main.Bar()",
 		"tracerr: file /tmp/not_exists_2.go not found",
-		"",
 	}
 	expected := strings.Join(expectedRows, "\n")
 	if output != expected {
@@ -405,7 +402,6 @@ func TestNoSourceFileColor(t *testing.T) {
 		"",
 		bold("/tmp/not_exists_2.go:43 main.Bar()"),
 		yellow("tracerr: file /tmp/not_exists_2.go not found"),
-		"",
 	}
 	expected := strings.Join(expectedRows, "\n")
 	if output != expected {
@@ -469,3 +465,519 @@ func red(in string) string {
 func yellow(in string) string {
 	return fmt.Sprintf("\x1b[33m%s\x1b[0m", in)
 }
+
+func TestFprint(t *testing.T) {
+	message := "runtime error: index out of range"
+	err := addFrameA(message)
+
+	cases := []struct {
+		Printer  func(w io.Writer)
+		Expected string
+	}{
+		{
+			Printer: func(w io.Writer) {
+				tracerr.Fprint(w, err)
+			},
+			Expected: tracerr.Sprint(err) + "\n",
+		},
+		{
+			Printer: func(w io.Writer) {
+				tracerr.FprintSource(w, err, 2, 1)
+			},
+			Expected: tracerr.SprintSource(err, 2, 1) + "\n",
+		},
+		{
+			Printer: func(w io.Writer) {
+				tracerr.FprintSourceColor(w, err, 4)
+			},
+			Expected: tracerr.SprintSourceColor(err, 4) + "\n",
+		},
+		{
+			Printer: func(w io.Writer) {
+				tracerr.Fprint(w, nil)
+			},
+			Expected: "\n",
+		},
+	}
+
+	for i, c := range cases {
+		var buf bytes.Buffer
+		c.Printer(&buf)
+		if buf.String() != c.Expected {
+			t.Errorf(
+				"case #%d: output = %#v; want %#v",
+				i, buf.String(), c.Expected,
+			)
+		}
+	}
+}
+
+func TestSourcePadding(t *testing.T) {
+	rows := make([]string, 120)
+	for i := range rows {
+		rows[i] = fmt.Sprintf("line%d", i+1)
+	}
+	path := t.TempDir() + "/padding.go"
+	if err := os.WriteFile(path, []byte(strings.Join(rows, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.Foo",
+				Line: 99,
+				Path: path,
+			},
+		},
+	)
+	header := path + ":99 main.Foo()"
+
+	output := tracerr.SprintSource(err, 2, 2)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		header,
+		" 97\tline97",
+		" 98\tline98",
+		" 99\tline99",
+		"100\tline100",
+		"101\tline101",
+	}, "\n")
+	if output != expected {
+		t.Errorf(
+			"tracerr.SprintSource(err, 2, 2) = %#v; want %#v",
+			output, expected,
+		)
+	}
+
+	output = tracerr.SprintSourceColor(err, 1, 1)
+	expected = strings.Join([]string{
+		"some error",
+		"",
+		bold(header),
+		black(" 98") + "\tline98",
+		red(" 99\tline99"),
+		black("100") + "\tline100",
+	}, "\n")
+	if output != expected {
+		t.Errorf(
+			"tracerr.SprintSourceColor(err, 1, 1) = %#v; want %#v",
+			output, expected,
+		)
+	}
+}
+
+func TestSetTabWidth(t *testing.T) {
+	defer tracerr.SetTabWidth(0)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameC",
+				Line: 17,
+				Path: "error_helper_test.go",
+			},
+		},
+	)
+
+	tracerr.SetTabWidth(4)
+	output := tracerr.SprintSource(err, 1, 1)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"16    func addFrameC(message string) error {",
+		"17        return tracerr.New(message)",
+		"18    }",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 1, 1) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintSourceColor(err, 0, 1)
+	expected = strings.Join([]string{
+		"some error",
+		"",
+		bold("error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()"),
+		red("17        return tracerr.New(message)"),
+		black("18") + "    }",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSourceColor(err, 0, 1) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetTabWidth(0)
+	output = tracerr.SprintSource(err, 0, 0)
+	if !strings.Contains(output, "17\t\treturn tracerr.New(message)") {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want tabs", output)
+	}
+}
+
+func TestSetSourceLineFormatter(t *testing.T) {
+	defer tracerr.SetSourceLineFormatter(nil)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameC",
+				Line: 17,
+				Path: "error_helper_test.go",
+			},
+		},
+	)
+
+	tracerr.SetSourceLineFormatter(func(lineNum int, text string, isTraced bool, colorized bool) string {
+		marker := "  "
+		if isTraced {
+			marker = ">>"
+		}
+		if colorized {
+			marker = "*" + marker
+		}
+		return fmt.Sprintf("%s %d | %s", marker, lineNum, text)
+	})
+	output := tracerr.SprintSource(err, 1, 1)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"   16 | func addFrameC(message string) error {",
+		">> 17 | \treturn tracerr.New(message)",
+		"   18 | }",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 1, 1) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintSourceColor(err, 0, 0)
+	expected = strings.Join([]string{
+		"some error",
+		"",
+		bold("error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()"),
+		"*>> 17 | \treturn tracerr.New(message)",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSourceColor(err, 0, 0) = %#v; want %#v", output, expected)
+	}
+}
+
+func TestSprintSourceGolden(t *testing.T) {
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameC",
+				Line: 17,
+				Path: "error_helper_test.go",
+			},
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameB",
+				Line: 13,
+				Path: "error_helper_test.go",
+			},
+		},
+	)
+	expected := "some error\n" +
+		"\n" +
+		"error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()\n" +
+		"16\tfunc addFrameC(message string) error {\n" +
+		"17\t\treturn tracerr.New(message)\n" +
+		"18\t}\n" +
+		"\n" +
+		"error_helper_test.go:13 github.com/ztrue/tracerr_test.addFrameB()\n" +
+		"12\tfunc addFrameB(message string) error {\n" +
+		"13\t\treturn addFrameC(message)\n" +
+		"14\t}"
+	output := tracerr.SprintSource(err, 1, 1)
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 1, 1) = %#v; want %#v", output, expected)
+	}
+
+	var buf bytes.Buffer
+	tracerr.FprintSource(&buf, err, 1, 1)
+	if buf.String() != expected+"\n" {
+		t.Errorf("tracerr.FprintSource(&buf, err, 1, 1) = %#v; want %#v", buf.String(), expected+"\n")
+	}
+}
+
+func TestSetDefaultWriter(t *testing.T) {
+	defer tracerr.SetDefaultWriter(nil)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.Foo",
+				Line: 42,
+				Path: "/src/main.go",
+			},
+		},
+	)
+	var buf bytes.Buffer
+	tracerr.SetDefaultWriter(&buf)
+	output := captureOutput(func() {
+		tracerr.Print(err)
+		tracerr.PrintSource(err)
+		tracerr.PrintSourceColor(err)
+	})
+	if output != "" {
+		t.Errorf("stdout = %#v; want empty", output)
+	}
+	expected := tracerr.Sprint(err) + "\n" + tracerr.SprintSource(err) + "\n" + tracerr.SprintSourceColor(err) + "\n"
+	if buf.String() != expected {
+		t.Errorf("buf.String() = %#v; want %#v", buf.String(), expected)
+	}
+
+	tracerr.SetDefaultWriter(nil)
+	output = captureOutput(func() {
+		tracerr.Print(err)
+	})
+	if output != tracerr.Sprint(err)+"\n" {
+		t.Errorf("stdout = %#v; want %#v", output, tracerr.Sprint(err)+"\n")
+	}
+}
+
+func TestSetFrameFormatter(t *testing.T) {
+	defer tracerr.SetFrameFormatter(nil)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.Foo",
+				Line: 42,
+				Path: "/src/main.go",
+			},
+			{
+				Func: "main.Bar",
+				Line: 43,
+				Path: "/src/main.go",
+			},
+		},
+	)
+
+	tracerr.SetFrameFormatter(func(frame tracerr.Frame) string {
+		return fmt.Sprintf("at %s (line %d)", frame.FuncName(), frame.Line)
+	})
+	output := tracerr.Sprint(err)
+	expected := "some error\nat Foo (line 42)\nat Bar (line 43)"
+	if output != expected {
+		t.Errorf("tracerr.Sprint(err) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetFrameFormatter(tracerr.FormatFileLine)
+	output = tracerr.Sprint(err)
+	expected = "some error\n/src/main.go:42\n/src/main.go:43"
+	if output != expected {
+		t.Errorf("tracerr.Sprint(err) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetFrameFormatter(tracerr.FormatFuncFileLine)
+	output = tracerr.Sprint(err)
+	expected = "some error\n/src/main.go:42 main.Foo\n/src/main.go:43 main.Bar"
+	if output != expected {
+		t.Errorf("tracerr.Sprint(err) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetFrameFormatter(nil)
+	output = tracerr.Sprint(err)
+	expected = "some error\n/src/main.go:42 main.Foo()\n/src/main.go:43 main.Bar()"
+	if output != expected {
+		t.Errorf("tracerr.Sprint(err) = %#v; want %#v", output, expected)
+	}
+}
+
+func TestSetSourceErrorHandler(t *testing.T) {
+	defer tracerr.SetSourceErrorHandler(nil)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.Foo",
+				Line: 1337,
+				Path: "error_helper_test.go",
+			},
+			{
+				Func: "main.Bar",
+				Line: 42,
+				Path: "/src/missing.go",
+			},
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameC",
+				Line: 17,
+				Path: "error_helper_test.go",
+			},
+		},
+	)
+
+	var handled []tracerr.Frame
+	tracerr.SetSourceErrorHandler(func(frame tracerr.Frame, err error) string {
+		handled = append(handled, frame)
+		return "source unavailable: " + frame.Path
+	})
+	output := tracerr.SprintSource(err, 0, 0)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"error_helper_test.go:1337 main.Foo()",
+		"source unavailable: error_helper_test.go",
+		"",
+		"/src/missing.go:42 main.Bar()",
+		"source unavailable: /src/missing.go",
+		"",
+		"error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"17\t\treturn tracerr.New(message)",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want %#v", output, expected)
+	}
+	if len(handled) != 2 || handled[0].Line != 1337 || handled[1].Line != 42 {
+		t.Errorf("handled = %#v; want frames with no source", handled)
+	}
+
+	tracerr.SetSourceErrorHandler(func(frame tracerr.Frame, err error) string {
+		return ""
+	})
+	output = tracerr.SprintSource(err, 0, 0)
+	expected = strings.Join([]string{
+		"some error",
+		"",
+		"error_helper_test.go:1337 main.Foo()",
+		"/src/missing.go:42 main.Bar()",
+		"",
+		"error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"17\t\treturn tracerr.New(message)",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetSourceErrorHandler(nil)
+	output = tracerr.SprintSource(err, 0, 0)
+	if !strings.Contains(output, "tracerr: file /src/missing.go not found") {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want default message", output)
+	}
+}
+
+func TestSprintCaller(t *testing.T) {
+	err := addFrameA("some error")
+	output := tracerr.SprintCaller(err, 1, 1)
+	output = regexp.MustCompile(`\S+/tracerr/`).ReplaceAllString(output, "")
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()",
+		"16\tfunc addFrameC(message string) error {",
+		"17\t\treturn tracerr.New(message)",
+		"18\t}",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintCaller(err, 1, 1) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintCaller(err)
+	if strings.Count(output, "\n\n") != 1 || strings.Contains(output, "addFrameB") {
+		t.Errorf("tracerr.SprintCaller(err) = %#v; want one frame", output)
+	}
+	if output := tracerr.SprintCaller(errors.New("some error")); output != "some error" {
+		t.Errorf("tracerr.SprintCaller(err) = %#v; want %#v", output, "some error")
+	}
+}
+
+func TestSetPrintWriter(t *testing.T) {
+	defer tracerr.SetDefaultWriter(nil)
+	defer tracerr.SetPrintWriter(tracerr.VariantPrint, nil)
+	defer tracerr.SetPrintWriter(tracerr.VariantPrintSource, nil)
+	defer tracerr.SetPrintWriter(tracerr.VariantPrintSourceColor, nil)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.Foo",
+				Line: 42,
+				Path: "/src/main.go",
+			},
+		},
+	)
+	var summary, detail, colored bytes.Buffer
+	tracerr.SetPrintWriter(tracerr.VariantPrint, &summary)
+	tracerr.SetPrintWriter(tracerr.VariantPrintSource, &detail)
+	tracerr.SetPrintWriter(tracerr.VariantPrintSourceColor, &colored)
+	output := captureOutput(func() {
+		tracerr.Print(err)
+		tracerr.PrintSource(err)
+		tracerr.PrintSourceColor(err)
+	})
+	if output != "" {
+		t.Errorf("stdout = %#v; want empty", output)
+	}
+	if summary.String() != tracerr.Sprint(err)+"\n" {
+		t.Errorf("summary.String() = %#v; want %#v", summary.String(), tracerr.Sprint(err)+"\n")
+	}
+	if detail.String() != tracerr.SprintSource(err)+"\n" {
+		t.Errorf("detail.String() = %#v; want %#v", detail.String(), tracerr.SprintSource(err)+"\n")
+	}
+	if colored.String() != tracerr.SprintSourceColor(err)+"\n" {
+		t.Errorf("colored.String() = %#v; want %#v", colored.String(), tracerr.SprintSourceColor(err)+"\n")
+	}
+
+	// Variant with no writer uses default writer.
+	var fallback bytes.Buffer
+	tracerr.SetDefaultWriter(&fallback)
+	tracerr.SetPrintWriter(tracerr.VariantPrintSource, nil)
+	tracerr.PrintSource(err)
+	tracerr.Print(err)
+	if fallback.String() != tracerr.SprintSource(err)+"\n" {
+		t.Errorf("fallback.String() = %#v; want %#v", fallback.String(), tracerr.SprintSource(err)+"\n")
+	}
+
+	tracerr.SetDefaultWriter(nil)
+	tracerr.SetPrintWriter(tracerr.VariantPrint, nil)
+	output = captureOutput(func() {
+		tracerr.Print(err)
+	})
+	if output != tracerr.Sprint(err)+"\n" {
+		t.Errorf("stdout = %#v; want %#v", output, tracerr.Sprint(err)+"\n")
+	}
+}
+
+func TestSetMaxOutputBytes(t *testing.T) {
+	defer tracerr.SetMaxOutputBytes(0)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{Func: "main.foo", Line: 1, Path: "/src/main.go"},
+			{Func: "main.bar", Line: 2, Path: "/src/main.go"},
+			{Func: "main.main", Line: 3, Path: "/src/main.go"},
+		},
+	)
+	full := tracerr.SprintSourceColor(err, 0, 0)
+
+	tracerr.SetMaxOutputBytes(120)
+	output := tracerr.SprintSourceColor(err, 0, 0)
+	if len(output) > 120 {
+		t.Errorf("len(output) = %#v; want at most %#v", len(output), 120)
+	}
+	if !strings.HasSuffix(output, "\n... (output truncated)") {
+		t.Errorf("output = %#v; want truncation marker", output)
+	}
+	// Output is cut by whole lines, so all color sequences are complete.
+	kept := strings.TrimSuffix(output, "... (output truncated)")
+	if !strings.HasPrefix(full, kept) {
+		t.Errorf("output = %#v; want prefix of %#v", output, full)
+	}
+	for _, row := range strings.Split(strings.TrimSuffix(kept, "\n"), "\n") {
+		if strings.Contains(row, "\x1b[") && !strings.HasSuffix(row, "\x1b[0m") {
+			t.Errorf("row = %#v; want complete color sequences", row)
+		}
+	}
+
+	tracerr.SetMaxOutputBytes(10)
+	if output := tracerr.Sprint(err); output != "... (output truncated)" {
+		t.Errorf("tracerr.Sprint(err) = %#v; want only marker", output)
+	}
+
+	tracerr.SetMaxOutputBytes(len(full))
+	if output := tracerr.SprintSourceColor(err, 0, 0); output != full {
+		t.Errorf("tracerr.SprintSourceColor(err, 0, 0) = %#v; want %#v", output, full)
+	}
+}
@@ -0,0 +1,93 @@
+package tracerr_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func rangeError(path string, line, endLine int) error {
+	return tracerr.CustomError(
+		errors.New("range error"),
+		[]tracerr.Frame{
+			{
+				Func:    "main.Parse",
+				Line:    line,
+				Path:    path,
+				EndLine: endLine,
+			},
+		},
+	)
+}
+
+func TestSprintSourceRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	writeSourceFile(t, path, "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine")
+
+	err := rangeError(path, 4, 6)
+	output := tracerr.SprintSourceColor(err, 2, 2)
+	expected := strings.Join([]string{
+		"range error",
+		"",
+		bold(path + ":4 main.Parse()"),
+		black("2") + "\ttwo",
+		black("3") + "\tthree",
+		red("4\tfour"),
+		red("5\tfive"),
+		red("6\tsix"),
+		black("7") + "\tseven",
+		black("8") + "\teight",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	// Range is cut at the end of file.
+	err = rangeError(path, 8, 20)
+	output = tracerr.SprintSource(err, 1, 1)
+	expected = strings.Join([]string{
+		"range error",
+		"",
+		path + ":8 main.Parse()",
+		"7\tseven",
+		"8\teight",
+		"9\tnine",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	// End line before line means a single line.
+	err = rangeError(path, 4, 2)
+	output = tracerr.SprintSourceColor(err, 1, 1)
+	expected = strings.Join([]string{
+		"range error",
+		"",
+		bold(path + ":4 main.Parse()"),
+		black("3") + "\tthree",
+		red("4\tfour"),
+		black("5") + "\tfive",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+}
+
+func TestParseJSONRange(t *testing.T) {
+	err := rangeError("/src/input.txt", 4, 6)
+	output, jsonErr := tracerr.SprintJSON(err)
+	if jsonErr != nil {
+		t.Fatalf("tracerr.SprintJSON(err) error = %#v; want nil", jsonErr)
+	}
+	parsed, parseErr := tracerr.ParseJSON([]byte(output))
+	if parseErr != nil {
+		t.Fatalf("tracerr.ParseJSON(%#v) error = %#v; want nil", output, parseErr)
+	}
+	frames := parsed.StackTrace()
+	if len(frames) != 1 || frames[0].EndLine != 6 {
+		t.Errorf("parsed.StackTrace() = %#v; want EndLine 6", frames)
+	}
+}
@@ -0,0 +1,115 @@
+package tracerr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RecoverError converts value returned by recover() to an error
+// with stack trace starting at panic site.
+// It must be called from a deferred function.
+//
+// If recovered value is not an error, it's stringified with fmt.Sprint,
+// if it's already of type Error, it's returned as is.
+// Recovered value is available with PanicValue.
+// It returns nil if recovered value is nil.
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			err = tracerr.RecoverError(r)
+//		}
+//	}()
+func RecoverError(recovered interface{}) error {
+	if recovered == nil {
+		return nil
+	}
+	return fromPanic(recovered)
+}
+
+// FromPanic converts value returned by recover() to an error
+// the same way as RecoverError does, but returns Error,
+// so recovered value, such as of a custom type, is available with PanicValue:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			err := tracerr.FromPanic(r)
+//			if status, ok := tracerr.PanicValue(err).(HTTPStatus); ok {
+//				// ...
+//			}
+//		}
+//	}()
+//
+// It returns nil if recovered value is nil.
+func FromPanic(recovered interface{}) Error {
+	if recovered == nil {
+		return nil
+	}
+	return fromPanic(recovered)
+}
+
+func fromPanic(recovered interface{}) Error {
+	if e, ok := recovered.(Error); ok {
+		return e
+	}
+	err, ok := recovered.(error)
+	if !ok {
+		err = fmt.Errorf("%v", recovered)
+	}
+	return created(&errorData{
+		err:         err,
+		frames:      panicFrames(stack(2)),
+		goroutineID: goroutineID(),
+		timestamp:   timestamp(),
+		panicValue:  recovered,
+	})
+}
+
+// PanicValue returns value recovered from panic, if error is created
+// by RecoverError or FromPanic, and nil otherwise.
+func (e *errorData) PanicValue() interface{} {
+	return e.panicValue
+}
+
+// PanicValue returns value recovered from panic, which the first Error in err chain
+// is created from by RecoverError or FromPanic, and nil otherwise.
+func PanicValue(err error) interface{} {
+	var e interface{ PanicValue() interface{} }
+	if !errors.As(err, &e) {
+		return nil
+	}
+	return e.PanicValue()
+}
+
+// Recover recovers from panic and sets err to an error
+// with stack trace starting at panic site, the same way as RecoverError.
+// It does nothing if there is no panic.
+// It must be deferred directly:
+//
+//	func foo() (err error) {
+//		defer tracerr.Recover(&err)
+//		// ...
+//	}
+func Recover(err *error) {
+	if recovered := recover(); recovered != nil {
+		*err = RecoverError(recovered)
+	}
+}
+
+// panicFrames removes frames of deferred function and panic machinery,
+// so the first frame is a panic site.
+// Frames are returned as is if there is no panic.
+func panicFrames(frames []Frame) []Frame {
+	for i, frame := range frames {
+		if frame.Func != "runtime.gopanic" {
+			continue
+		}
+		// Runtime errors have more runtime frames, such as runtime.goPanicIndex.
+		i++
+		for i < len(frames) && strings.HasPrefix(frames[i].Func, "runtime.") {
+			i++
+		}
+		return frames[i:]
+	}
+	return frames
+}
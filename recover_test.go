@@ -0,0 +1,177 @@
+package tracerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func panicWithValue(value interface{}) {
+	panic(value)
+}
+
+func panicWithIndex(values []int) int {
+	return values[len(values)]
+}
+
+func recoverError(fn func()) (err error) {
+	defer func() {
+		err = tracerr.RecoverError(recover())
+	}()
+	fn()
+	return nil
+}
+
+func recoverDeferred(fn func()) (err error) {
+	defer tracerr.Recover(&err)
+	fn()
+	return nil
+}
+
+type RecoverTestCase struct {
+	Recover         func(func()) error
+	Panic           func()
+	ExpectedMessage string
+	ExpectedFunc    string
+	ExpectedLine    int
+}
+
+func TestRecover(t *testing.T) {
+	original := errors.New("original error")
+	cases := []RecoverTestCase{
+		{
+			Recover: recoverError,
+			Panic: func() {
+				panicWithValue("string value")
+			},
+			ExpectedMessage: "string value",
+			ExpectedFunc:    "github.com/ztrue/tracerr_test.panicWithValue",
+			ExpectedLine:    11,
+		},
+		{
+			Recover: recoverDeferred,
+			Panic: func() {
+				panicWithValue(original)
+			},
+			ExpectedMessage: "original error",
+			ExpectedFunc:    "github.com/ztrue/tracerr_test.panicWithValue",
+			ExpectedLine:    11,
+		},
+		{
+			Recover: recoverDeferred,
+			Panic: func() {
+				panicWithIndex([]int{1, 2})
+			},
+			ExpectedMessage: "runtime error: index out of range [2] with length 2",
+			ExpectedFunc:    "github.com/ztrue/tracerr_test.panicWithIndex",
+			ExpectedLine:    15,
+		},
+		{
+			Recover: recoverDeferred,
+			Panic: func() {
+				panicWithValue(42)
+			},
+			ExpectedMessage: "42",
+			ExpectedFunc:    "github.com/ztrue/tracerr_test.panicWithValue",
+			ExpectedLine:    11,
+		},
+	}
+
+	for i, c := range cases {
+		err := c.Recover(c.Panic)
+		if err == nil {
+			t.Fatalf("cases[%#v]: err = nil; want error", i)
+		}
+		if err.Error() != c.ExpectedMessage {
+			t.Errorf("cases[%#v]: err.Error() = %#v; want %#v", i, err.Error(), c.ExpectedMessage)
+		}
+		frames := tracerr.StackTrace(err)
+		if len(frames) == 0 {
+			t.Fatalf("cases[%#v]: tracerr.StackTrace(err) is empty", i)
+		}
+		if frames[0].Func != c.ExpectedFunc || frames[0].Line != c.ExpectedLine {
+			t.Errorf(
+				"cases[%#v]: tracerr.StackTrace(err)[0] = %#v; want %s at line %d",
+				i, frames[0], c.ExpectedFunc, c.ExpectedLine,
+			)
+		}
+	}
+
+	if !errors.Is(recoverDeferred(func() { panicWithValue(original) }), original) {
+		t.Errorf("errors.Is(err, original) = false; want true")
+	}
+	if err := recoverDeferred(func() {}); err != nil {
+		t.Errorf("recoverDeferred(no panic) = %#v; want nil", err)
+	}
+	if err := recoverError(func() {}); err != nil {
+		t.Errorf("recoverError(no panic) = %#v; want nil", err)
+	}
+}
+
+type httpStatus struct {
+	Code int
+}
+
+func fromPanic(fn func()) (err tracerr.Error) {
+	defer func() {
+		err = tracerr.FromPanic(recover())
+	}()
+	fn()
+	return nil
+}
+
+type FromPanicTestCase struct {
+	Value           interface{}
+	ExpectedMessage string
+}
+
+func TestFromPanic(t *testing.T) {
+	original := errors.New("original error")
+	cases := []FromPanicTestCase{
+		{
+			Value:           "string value",
+			ExpectedMessage: "string value",
+		},
+		{
+			Value:           original,
+			ExpectedMessage: "original error",
+		},
+		{
+			Value:           httpStatus{Code: 404},
+			ExpectedMessage: "{404}",
+		},
+	}
+
+	for i, c := range cases {
+		err := fromPanic(func() {
+			panicWithValue(c.Value)
+		})
+		if err == nil {
+			t.Fatalf("cases[%#v]: err = nil; want error", i)
+		}
+		if err.Error() != c.ExpectedMessage {
+			t.Errorf("cases[%#v]: err.Error() = %#v; want %#v", i, err.Error(), c.ExpectedMessage)
+		}
+		if tracerr.PanicValue(err) != c.Value {
+			t.Errorf("cases[%#v]: tracerr.PanicValue(err) = %#v; want %#v", i, tracerr.PanicValue(err), c.Value)
+		}
+		if frames := err.StackTrace(); len(frames) == 0 || frames[0].Func != "github.com/ztrue/tracerr_test.panicWithValue" {
+			t.Errorf("cases[%#v]: err.StackTrace() = %#v; want panicWithValue first", i, frames)
+		}
+	}
+
+	err := fromPanic(func() {
+		panicWithValue(httpStatus{Code: 503})
+	})
+	wrapped := tracerr.Wrapf(err, "handle request")
+	if status, ok := tracerr.PanicValue(wrapped).(httpStatus); !ok || status.Code != 503 {
+		t.Errorf("tracerr.PanicValue(wrapped) = %#v; want %#v", tracerr.PanicValue(wrapped), httpStatus{Code: 503})
+	}
+	if value := tracerr.PanicValue(tracerr.New("some error")); value != nil {
+		t.Errorf("tracerr.PanicValue(tracerr.New(...)) = %#v; want nil", value)
+	}
+	if err := fromPanic(func() {}); err != nil {
+		t.Errorf("fromPanic(no panic) = %#v; want nil", err)
+	}
+}
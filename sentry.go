@@ -0,0 +1,35 @@
+package tracerr
+
+// StackFrame is a frame in a shape of Sentry stack frame,
+// so it can be mapped to sentry.Frame field by field
+// with no dependency on Sentry SDK.
+type StackFrame struct {
+	// Function is a function name, such as github.com/john/doe/foobar.Foo.
+	Function string `json:"function"`
+	// Filename is a full path to a file.
+	Filename string `json:"filename"`
+	// Lineno is a line number in a file.
+	Lineno int `json:"lineno"`
+}
+
+// SentryFrames returns stack trace of err as Sentry stack frames.
+//
+// Unlike StackTrace, frames are ordered oldest first,
+// with the frame where error was created being the last one,
+// which is the order Sentry expects.
+// It returns nil if err has no stack trace.
+func SentryFrames(err error) []StackFrame {
+	stackTrace := StackTrace(err)
+	if stackTrace == nil {
+		return nil
+	}
+	frames := make([]StackFrame, len(stackTrace))
+	for i, frame := range stackTrace {
+		frames[len(stackTrace)-1-i] = StackFrame{
+			Function: frame.Func,
+			Filename: frame.Path,
+			Lineno:   frame.Line,
+		}
+	}
+	return frames
+}
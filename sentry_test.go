@@ -0,0 +1,37 @@
+package tracerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestSentryFrames(t *testing.T) {
+	err := tracerr.CustomError(errors.New("sentry error"), []tracerr.Frame{
+		{Func: "main.foo", Line: 42, Path: "/src/foo.go"},
+		{Func: "main.main", Line: 7, Path: "/src/main.go"},
+	})
+	frames := tracerr.SentryFrames(err)
+	expected := []tracerr.StackFrame{
+		{Function: "main.main", Filename: "/src/main.go", Lineno: 7},
+		{Function: "main.foo", Filename: "/src/foo.go", Lineno: 42},
+	}
+	if len(frames) != len(expected) {
+		t.Fatalf("len(frames) = %#v; want %#v", len(frames), len(expected))
+	}
+	for i, frame := range frames {
+		if frame != expected[i] {
+			t.Errorf("frames[%#v] = %#v; want %#v", i, frame, expected[i])
+		}
+	}
+}
+
+func TestSentryFramesNoStackTrace(t *testing.T) {
+	if frames := tracerr.SentryFrames(nil); frames != nil {
+		t.Errorf("tracerr.SentryFrames(nil) = %#v; want nil", frames)
+	}
+	if frames := tracerr.SentryFrames(errors.New("plain")); frames != nil {
+		t.Errorf("tracerr.SentryFrames(plain) = %#v; want nil", frames)
+	}
+}
@@ -0,0 +1,58 @@
+package tracerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func newValidationError(message string) error {
+	return tracerr.NewWithSkip("validation: "+message, 1)
+}
+
+func wrapValidationError(err error) error {
+	return tracerr.WrapWithSkip(err, 1)
+}
+
+func TestNewWithSkip(t *testing.T) {
+	err := newValidationError("bad input")
+	frames := tracerr.StackTrace(err)
+	if len(frames) == 0 {
+		t.Fatalf("len(frames) = 0; want > 0")
+	}
+	if name := frames[0].FuncName(); name != "TestNewWithSkip" {
+		t.Errorf("frames[0].FuncName() = %#v; want %#v", name, "TestNewWithSkip")
+	}
+
+	err = tracerr.NewWithSkip("no skip", 0)
+	if name := tracerr.StackTrace(err)[0].FuncName(); name != "TestNewWithSkip" {
+		t.Errorf("frames[0].FuncName() = %#v; want %#v", name, "TestNewWithSkip")
+	}
+	err = tracerr.NewWithSkip("negative skip", -1)
+	if name := tracerr.StackTrace(err)[0].FuncName(); name != "TestNewWithSkip" {
+		t.Errorf("frames[0].FuncName() = %#v; want %#v", name, "TestNewWithSkip")
+	}
+}
+
+func TestWrapWithSkip(t *testing.T) {
+	err := wrapValidationError(errors.New("bad input"))
+	frames := tracerr.StackTrace(err)
+	if len(frames) == 0 {
+		t.Fatalf("len(frames) = 0; want > 0")
+	}
+	if name := frames[0].FuncName(); name != "TestWrapWithSkip" {
+		t.Errorf("frames[0].FuncName() = %#v; want %#v", name, "TestWrapWithSkip")
+	}
+	if err.Error() != "bad input" {
+		t.Errorf("err.Error() = %#v; want %#v", err.Error(), "bad input")
+	}
+
+	err = tracerr.WrapWithSkip(errors.New("no skip"), 0)
+	if name := tracerr.StackTrace(err)[0].FuncName(); name != "TestWrapWithSkip" {
+		t.Errorf("frames[0].FuncName() = %#v; want %#v", name, "TestWrapWithSkip")
+	}
+	if err := tracerr.WrapWithSkip(nil, 1); err != nil {
+		t.Errorf("tracerr.WrapWithSkip(nil, 1) = %#v; want nil", err)
+	}
+}
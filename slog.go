@@ -0,0 +1,35 @@
+package tracerr
+
+import (
+	"log/slog"
+)
+
+// LogValue implements slog.LogValuer,
+// so stack trace is kept when error is passed to slog as an attribute.
+func (e *errorData) LogValue() slog.Value {
+	stackTrace := e.StackTrace()
+	frames := make([]jsonFrame, 0, len(stackTrace))
+	for _, frame := range stackTrace {
+		frames = append(frames, jsonFrame{
+			Func: frame.Func,
+			File: frame.Path,
+			Line: frame.Line,
+		})
+	}
+	return slog.GroupValue(
+		slog.String("message", e.Error()),
+		slog.Any("frames", frames),
+	)
+}
+
+// LogValue returns slog value for err, which is a group of message and frames.
+// It will be just an error message if err is not of type Error.
+func LogValue(err error) slog.Value {
+	if err == nil {
+		return slog.AnyValue(nil)
+	}
+	if v, ok := err.(slog.LogValuer); ok {
+		return v.LogValue()
+	}
+	return slog.StringValue(err.Error())
+}
@@ -0,0 +1,68 @@
+package tracerr_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestLogValue(t *testing.T) {
+	err := addFrameA("slog error")
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("failed", "err", err)
+
+	var record struct {
+		Err struct {
+			Message string `json:"message"`
+			Frames  []struct {
+				Func string `json:"func"`
+				File string `json:"file"`
+				Line int    `json:"line"`
+			} `json:"frames"`
+		} `json:"err"`
+	}
+	if jsonErr := json.Unmarshal(buf.Bytes(), &record); jsonErr != nil {
+		t.Fatalf("json.Unmarshal(%#v) error = %#v; want nil", buf.String(), jsonErr)
+	}
+	if record.Err.Message != "slog error" {
+		t.Errorf("record.Err.Message = %#v; want %#v", record.Err.Message, "slog error")
+	}
+	frames := tracerr.StackTrace(err)
+	if len(record.Err.Frames) != len(frames) {
+		t.Fatalf("len(record.Err.Frames) = %#v; want %#v", len(record.Err.Frames), len(frames))
+	}
+	first := record.Err.Frames[0]
+	if first.Func != "github.com/ztrue/tracerr_test.addFrameC" {
+		t.Errorf("record.Err.Frames[0].Func = %#v; want %#v", first.Func, "github.com/ztrue/tracerr_test.addFrameC")
+	}
+	if first.Line != 17 {
+		t.Errorf("record.Err.Frames[0].Line = %#v; want %#v", first.Line, 17)
+	}
+	if !strings.HasSuffix(first.File, "/tracerr/error_helper_test.go") {
+		t.Errorf("record.Err.Frames[0].File = %#v; want to has suffix %#v", first.File, "/tracerr/error_helper_test.go")
+	}
+}
+
+func TestLogValueNotInstance(t *testing.T) {
+	value := tracerr.LogValue(errors.New("regular error"))
+	if value.Kind() != slog.KindString || value.String() != "regular error" {
+		t.Errorf(
+			"tracerr.LogValue(err) = %#v; want %#v",
+			value, slog.StringValue("regular error"),
+		)
+	}
+
+	value = tracerr.LogValue(tracerr.New("traced error"))
+	if value.Kind() != slog.KindGroup {
+		t.Errorf(
+			"tracerr.LogValue(err).Kind() = %#v; want %#v",
+			value.Kind(), slog.KindGroup,
+		)
+	}
+}
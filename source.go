@@ -0,0 +1,220 @@
+package tracerr
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var sourceMutex sync.RWMutex
+
+var sourceFS fs.FS = osFS{}
+
+// goRoot is GOROOT environment variable by default, if it's set.
+var goRoot = os.Getenv("GOROOT")
+
+// DefaultMaxSourceFileBytes is a default maximum size of source file to read.
+const DefaultMaxSourceFileBytes = 4 << 20
+
+var maxSourceFileBytes atomic.Int64
+
+func init() {
+	maxSourceFileBytes.Store(DefaultMaxSourceFileBytes)
+}
+
+// errFileTooLarge is returned by readSource for files over SetMaxSourceFileBytes limit.
+var errFileTooLarge = errors.New("file too large")
+
+// SetSourceFS sets filesystem to read source fragments from.
+// By default files are read from OS filesystem.
+//
+// Absolute frame paths are translated to paths valid for fsys
+// by removing leading slash, so "/src/main.go" is read as "src/main.go".
+// Pass nil to restore OS filesystem.
+//
+// Source cache is cleared, since files could differ between filesystems.
+func SetSourceFS(fsys fs.FS) {
+	if fsys == nil {
+		fsys = osFS{}
+	}
+	sourceMutex.Lock()
+	sourceFS = fsys
+	sourceMutex.Unlock()
+	ClearSourceCache()
+}
+
+// SetGoRoot sets a local GOROOT to read source fragments of standard library frames from,
+// when they are not found by their original paths, such as when binary
+// is built on a machine with Go installed in a different location.
+// By default it's GOROOT environment variable, if it's set.
+// Pass an empty string to read standard library sources only by original paths.
+//
+// Source cache is cleared, since files could differ between GOROOTs.
+func SetGoRoot(path string) {
+	sourceMutex.Lock()
+	goRoot = path
+	sourceMutex.Unlock()
+	ClearSourceCache()
+}
+
+// SetMaxSourceFileBytes sets a maximum size of source file in bytes,
+// DefaultMaxSourceFileBytes by default.
+// Larger files, such as generated or minified ones, are not read and cached,
+// so there is a message instead of source fragment.
+// Files of OS filesystem are never read over the limit, even if their size is unknown,
+// such as devices or files behind symlinks to them.
+// If n <= 0 files of any size are read.
+//
+// Source cache is cleared, so files over a new limit are not kept in it.
+func SetMaxSourceFileBytes(n int) {
+	maxSourceFileBytes.Store(int64(n))
+	ClearSourceCache()
+}
+
+// osFS is a thin wrapper over OS filesystem, which takes frame paths as is.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// readSource reads file by frame path from source filesystem.
+// Standard library files, which are not found, are read from local GOROOT.
+func readSource(path string) ([]byte, error) {
+	sourceMutex.RLock()
+	fsys := sourceFS
+	root := goRoot
+	sourceMutex.RUnlock()
+	b, err := readFile(fsys, path)
+	if err == nil {
+		return b, nil
+	}
+	if local, ok := goRootPath(root, path); ok {
+		if b, localErr := readFile(fsys, local); localErr == nil {
+			return b, nil
+		}
+	}
+	return nil, err
+}
+
+// readFile reads file from fsys, up to SetMaxSourceFileBytes limit.
+func readFile(fsys fs.FS, path string) ([]byte, error) {
+	_, isOS := fsys.(osFS)
+	name := path
+	if !isOS {
+		name = fsPath(path)
+	}
+	limit := maxSourceFileBytes.Load()
+	if limit <= 0 {
+		return fs.ReadFile(fsys, name)
+	}
+	var b []byte
+	var err error
+	if isOS {
+		b, err = readOSFile(name, limit)
+	} else {
+		if info, err := fs.Stat(fsys, name); err == nil && info.Size() > limit {
+			return nil, errFileTooLarge
+		}
+		b, err = fs.ReadFile(fsys, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, errFileTooLarge
+	}
+	return b, nil
+}
+
+// readOSFile reads no more than limit+1 bytes of file, even if its size is unknown,
+// such as for devices, so it's enough to tell whether file is over the limit.
+// File is opened once, and its size is checked before reading.
+func readOSFile(path string, limit int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.Size() > limit {
+		return nil, errFileTooLarge
+	}
+	return io.ReadAll(io.LimitReader(f, limit+1))
+}
+
+// goRootPath remaps path of standard library file, such as /build/go/src/fmt/print.go,
+// to the same file in local GOROOT.
+// Standard library packages are told by first path element with no dot in it.
+func goRootPath(root, path string) (string, bool) {
+	if root == "" {
+		return "", false
+	}
+	slashed := filepath.ToSlash(path)
+	i := strings.LastIndex(slashed, "/src/")
+	if i < 0 {
+		return "", false
+	}
+	rel := slashed[i+len("/src/"):]
+	first, _, _ := strings.Cut(rel, "/")
+	if first == "" || strings.Contains(first, ".") {
+		return "", false
+	}
+	local := filepath.Join(root, "src", filepath.FromSlash(rel))
+	if local == filepath.Clean(path) {
+		return "", false
+	}
+	return local, true
+}
+
+// fsPath translates frame path to a path valid for fs.FS.
+func fsPath(path string) string {
+	path = strings.TrimPrefix(path, filepath.VolumeName(path))
+	return strings.TrimLeft(filepath.ToSlash(path), "/")
+}
+
+// processStart is used as build time if binary could not be found.
+var processStart = time.Now()
+
+// buildTime returns modification time of running binary,
+// which is about the time it was built.
+var buildTime = sync.OnceValue(func() time.Time {
+	path, err := os.Executable()
+	if err != nil {
+		return processStart
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return processStart
+	}
+	return info.ModTime()
+})
+
+// sourceModified reports whether file is modified after running binary was built,
+// so its source may not match frame lines.
+// Files with unknown modification time, such as embedded ones, are not reported.
+func sourceModified(path string) bool {
+	sourceMutex.RLock()
+	fsys := sourceFS
+	sourceMutex.RUnlock()
+	var info fs.FileInfo
+	var err error
+	if _, ok := fsys.(osFS); ok {
+		info, err = os.Stat(path)
+	} else {
+		info, err = fs.Stat(fsys, fsPath(path))
+	}
+	if err != nil || info.ModTime().IsZero() {
+		return false
+	}
+	return info.ModTime().After(buildTime())
+}
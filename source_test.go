@@ -0,0 +1,419 @@
+package tracerr_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestSetSourceFS(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/main.go": {
+			Data: []byte("package main\n\nfunc main() {\n\tpanic(1)\n}\n"),
+		},
+	})
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.main",
+				Line: 4,
+				Path: "/src/main.go",
+			},
+			{
+				Func: "main.foo",
+				Line: 1,
+				Path: "/src/foo.go",
+			},
+		},
+	)
+	output := tracerr.SprintSource(err, 1, 1)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"/src/main.go:4 main.main()",
+		"3\tfunc main() {",
+		"4\t\tpanic(1)",
+		"5\t}",
+		"",
+		"/src/foo.go:1 main.foo()",
+		"tracerr: file /src/foo.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf(
+			"tracerr.SprintSource(err, 1, 1) = %#v; want %#v",
+			output, expected,
+		)
+	}
+
+	// OS filesystem is restored.
+	tracerr.SetSourceFS(nil)
+	output = tracerr.SprintSource(err, 1, 1)
+	if !strings.Contains(output, "tracerr: file /src/main.go not found") {
+		t.Errorf(
+			"tracerr.SprintSource(err, 1, 1) = %#v; want file not found",
+			output,
+		)
+	}
+}
+
+func TestSourceCRLF(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/main.go": {
+			Data: []byte("package main\r\n\r\nfunc main() {\r\n\tpanic(1)\r\n}\r\n"),
+		},
+	})
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.main",
+				Line: 4,
+				Path: "/src/main.go",
+			},
+		},
+	)
+	output := tracerr.SprintSource(err, 1, 1)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"/src/main.go:4 main.main()",
+		"3\tfunc main() {",
+		"4\t\tpanic(1)",
+		"5\t}",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 1, 1) = %#v; want %#v", output, expected)
+	}
+	if strings.Contains(tracerr.SprintSourceColor(err, 1, 1), "\r") {
+		t.Errorf("tracerr.SprintSourceColor(err, 1, 1) contains carriage return")
+	}
+}
+
+func TestSetMaxSourceLineWidth(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	defer tracerr.SetMaxSourceLineWidth(0)
+	long := "var s = \"" + strings.Repeat("é", 490) + "\""
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/main.go": {
+			Data: []byte("package main\n\n" + long + "\n"),
+		},
+	})
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.init",
+				Line: 3,
+				Path: "/src/main.go",
+			},
+		},
+	)
+
+	tracerr.SetMaxSourceLineWidth(80)
+	output := tracerr.SprintSource(err, 0, 0)
+	truncated := "var s = \"" + strings.Repeat("é", 70) + "…"
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"/src/main.go:3 main.init()",
+		"3\t" + truncated,
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetMaxSourceLineWidth(0)
+	output = tracerr.SprintSource(err, 0, 0)
+	if !strings.HasSuffix(output, "3\t"+long) {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want full line", output)
+	}
+}
+
+func TestSetGoRoot(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	defer tracerr.SetGoRoot(os.Getenv("GOROOT"))
+	tracerr.SetSourceFS(fstest.MapFS{
+		"local/go/src/strings/builder.go": {
+			Data: []byte("package strings\n\nfunc (b *Builder) grow(n int) {\n\tpanic(n)\n}\n"),
+		},
+	})
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "strings.(*Builder).grow",
+				Line: 4,
+				Path: "/build/go/src/strings/builder.go",
+			},
+			{
+				Func: "github.com/john/doe.Foo",
+				Line: 4,
+				Path: "/build/gopath/src/github.com/john/doe/foo.go",
+			},
+		},
+	)
+
+	tracerr.SetGoRoot("/local/go")
+	output := tracerr.SprintSource(err, 0, 0)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"/build/go/src/strings/builder.go:4 strings.(*Builder).grow()",
+		"4\t\tpanic(n)",
+		"",
+		"/build/gopath/src/github.com/john/doe/foo.go:4 github.com/john/doe.Foo()",
+		"tracerr: file /build/gopath/src/github.com/john/doe/foo.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetGoRoot("")
+	output = tracerr.SprintSource(err, 0, 0)
+	if !strings.Contains(output, "tracerr: file /build/go/src/strings/builder.go not found") {
+		t.Errorf("tracerr.SprintSource(err, 0, 0) = %#v; want file not found", output)
+	}
+}
+
+func TestWithStaleSourceWarning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	writeSourceFile(t, path, "package main")
+	err := sourceFileError(path)
+	warning := "tracerr: file " + path + " is modified after binary was built, source may not match"
+
+	old := time.Now().Add(-365 * 24 * time.Hour)
+	if chErr := os.Chtimes(path, old, old); chErr != nil {
+		t.Fatal(chErr)
+	}
+	output := tracerr.SprintWithOptions(err, tracerr.WithSource(), tracerr.WithStaleSourceWarning(true))
+	if strings.Contains(output, warning) {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want no warning", output)
+	}
+
+	future := time.Now().Add(time.Hour)
+	if chErr := os.Chtimes(path, future, future); chErr != nil {
+		t.Fatal(chErr)
+	}
+	output = tracerr.SprintWithOptions(err, tracerr.WithSource(), tracerr.WithStaleSourceWarning(true))
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		path + ":1 main.Foo()",
+		warning,
+		"1\tpackage main",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithSource())
+	if strings.Contains(output, warning) {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want no warning", output)
+	}
+}
+
+func TestWithDedentSource(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/main.go": {
+			Data: []byte(strings.Join([]string{
+				"func main() {",
+				"\tfor {",
+				"\t\tif ok {",
+				"\t\t\tfor _, v := range values {",
+				"\t\t\t\tif v > 0 {",
+				"",
+				"\t\t\t\t\tpanic(v)",
+				"\t\t\t\t}",
+				"\t\t\t}",
+				"\t\t}",
+				"\t}",
+				"}",
+			}, "\n")),
+		},
+	})
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "main.main",
+				Line: 7,
+				Path: "/src/main.go",
+				Col:  6,
+			},
+		},
+	)
+	defer tracerr.SetTabWidth(0)
+	tracerr.SetTabWidth(2)
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithSource(2, 1), tracerr.WithDedentSource(true))
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"/src/main.go:7 main.main()",
+		"5  if v > 0 {",
+		"6  ",
+		"7    panic(v)",
+		"     ^",
+		"8  }",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithSource(2, 1), tracerr.WithColor(true), tracerr.WithDedentSource(true))
+	if !strings.Contains(output, red("7    panic(v)")+"\n"+red("     ^")) {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want dedented traced line", output)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithSource(2, 1))
+	if !strings.Contains(output, "\n7            panic(v)\n") {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want indented source", output)
+	}
+}
+
+func TestWithPosition(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		// 100 bytes: 10 lines of 9 bytes with LF.
+		"src/gen.go": {Data: []byte(strings.Repeat("// line.\n", 10) + "0123456789")},
+	})
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{Func: "main.gen", Line: 5, Path: "/src/gen.go"},
+			{Func: "main.main", Line: 1, Path: "/src/gen.go"},
+			{Func: "main.foo", Line: 1, Path: "/src/missing.go"},
+		},
+	)
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithPosition(tracerr.PositionOffset))
+	expected := strings.Join([]string{
+		"some error",
+		"/src/gen.go:5 main.gen() (offset 36)",
+		"/src/gen.go:1 main.main() (offset 0)",
+		"/src/missing.go:1 main.foo()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithPosition(tracerr.PositionPercent))
+	expected = strings.Join([]string{
+		"some error",
+		"/src/gen.go:5 main.gen() (36%)",
+		"/src/gen.go:1 main.main() (0%)",
+		"/src/missing.go:1 main.foo()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	if output := tracerr.Sprint(err); strings.Contains(output, "offset") || strings.Contains(output, "%") {
+		t.Errorf("tracerr.Sprint(err) = %#v; want no position", output)
+	}
+}
+
+func TestWithPositionCRLF(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		// 110 bytes: 10 lines of 10 bytes with CRLF.
+		"src/gen.go": {Data: []byte(strings.Repeat("// line.\r\n", 10) + "0123456789")},
+	})
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{Func: "main.gen", Line: 5, Path: "/src/gen.go"},
+			{Func: "main.main", Line: 11, Path: "/src/gen.go"},
+		},
+	)
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithPosition(tracerr.PositionOffset), tracerr.WithSource(0, 0))
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		"/src/gen.go:5 main.gen() (offset 40)",
+		"5\t// line.",
+		"",
+		"/src/gen.go:11 main.main() (offset 100)",
+		"11\t0123456789",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithPosition(tracerr.PositionPercent))
+	if !strings.Contains(output, "main.main() (90%)") {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want position 90%%", output)
+	}
+}
+
+func TestSetMaxSourceFileBytes(t *testing.T) {
+	defer tracerr.SetMaxSourceFileBytes(tracerr.DefaultMaxSourceFileBytes)
+	path := filepath.Join(t.TempDir(), "main.go")
+	writeSourceFile(t, path, "package main\n\nfunc main() {}\n")
+	err := sourceFileError(path)
+
+	tracerr.SetMaxSourceFileBytes(10)
+	output := tracerr.SprintSource(err)
+	expected := strings.Join([]string{
+		"some error",
+		"",
+		path + ":1 main.Foo()",
+		"tracerr: file " + path + " is too large, max 10 bytes",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintSource(err) = %#v; want %#v", output, expected)
+	}
+
+	tracerr.SetMaxSourceFileBytes(100)
+	output = tracerr.SprintSource(err)
+	if !strings.Contains(output, "1\tpackage main") {
+		t.Errorf("tracerr.SprintSource(err) = %#v; want source fragment", output)
+	}
+
+	tracerr.SetMaxSourceFileBytes(0)
+	output = tracerr.SprintSource(err)
+	if !strings.Contains(output, "1\tpackage main") {
+		t.Errorf("tracerr.SprintSource(err) = %#v; want source fragment", output)
+	}
+}
+
+type SourceLineWidthTestCase struct {
+	Line     string
+	Width    int
+	Expected string
+}
+
+func TestSetMaxSourceLineWidthColumns(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	defer tracerr.SetMaxSourceLineWidth(0)
+	cases := []SourceLineWidthTestCase{
+		// Wide characters take 2 columns.
+		{"s := \"日本語日本語\"", 12, "s := \"日本…"},
+		{"s := \"日本語\"", 13, "s := \"日本語\""},
+		// Tabs take up to the next tab stop.
+		{"\t\tx = 1234567890", 20, "\t\tx =…"},
+		// Combining marks take no columns.
+		{"s := \"e\u0301e\u0301\"", 9, "s := \"e\u0301e\u0301\""},
+	}
+	for _, c := range cases {
+		tracerr.SetSourceFS(fstest.MapFS{
+			"src/main.go": {Data: []byte(c.Line)},
+		})
+		tracerr.SetMaxSourceLineWidth(c.Width)
+		output := tracerr.SprintSource(sourceFileError("/src/main.go"), 0, 0)
+		if !strings.HasSuffix(output, "\n1\t"+c.Expected) {
+			t.Errorf("line = %#v, width = %#v: output = %#v; want last row %#v", c.Line, c.Width, output, "1\t"+c.Expected)
+		}
+	}
+}
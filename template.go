@@ -0,0 +1,121 @@
+package tracerr
+
+import (
+	"strings"
+	"text/template"
+)
+
+// TemplateData is data SprintTemplate executes template with.
+type TemplateData struct {
+	// Message contains error message with no stack trace.
+	Message string
+	// Frames contains frames to display, filtered and ordered by options.
+	Frames []TemplateFrame
+	// Settings contains options output is configured with.
+	Settings TemplateSettings
+}
+
+// TemplateFrame is a single frame of TemplateData.
+type TemplateFrame struct {
+	// Header contains frame header, the same as in Sprint output,
+	// such as "main.go:10 main.main()" or "... (3 frames in dependencies)".
+	Header string
+	// Func contains a function name.
+	Func string
+	// Path contains a file path, as it's displayed in Sprint output.
+	Path string
+	// Line contains a line number.
+	Line int
+	// Source contains source fragment, if source is displayed.
+	Source []TemplateLine
+	// SourceError describes why source is not available, such as file not found.
+	SourceError string
+}
+
+// TemplateLine is a line of source fragment of TemplateFrame.
+type TemplateLine struct {
+	// Number contains a line number.
+	Number int
+	// Text contains a line of source code.
+	Text string
+	// Traced is true for a traced line or a line in traced range.
+	Traced bool
+}
+
+// TemplateSettings contains options output is configured with.
+type TemplateSettings struct {
+	// WithSource is true if source fragments are displayed.
+	WithSource bool
+	// Before contains a number of source lines before traced line.
+	Before int
+	// After contains a number of source lines after traced line.
+	After int
+	// Colorized is true for colorized output, see WithColor.
+	Colorized bool
+	// ReverseFrames is true if frames are ordered from outermost to innermost.
+	ReverseFrames bool
+}
+
+// SprintTemplate returns error output rendered by tmpl, which is executed with TemplateData.
+// Frames and source fragments are configured by opts the same way as in SprintWithOptions,
+// so with no options there is no source:
+//
+//	tmpl := template.Must(template.New("error").Parse(
+//		"{{.Message}}\n" +
+//			"{{range .Frames}}  at {{.Func}} ({{.Path}}:{{.Line}})\n" +
+//			"{{range .Source}}{{if .Traced}}> {{else}}  {{end}}{{.Number}}: {{.Text}}\n{{end}}" +
+//			"{{end}}",
+//	))
+//	text, err := tracerr.SprintTemplate(err, tmpl, tracerr.WithSource(3))
+//
+// Error is returned if template execution fails.
+func SprintTemplate(err error, tmpl *template.Template, opts ...Option) (string, error) {
+	var b strings.Builder
+	if execErr := tmpl.Execute(&b, templateData(err, newOptions(opts))); execErr != nil {
+		return "", execErr
+	}
+	return b.String(), nil
+}
+
+func templateData(err error, o *options) TemplateData {
+	before, after, withSource := calcRows(o.nums)
+	data := TemplateData{
+		Settings: TemplateSettings{
+			WithSource:    withSource,
+			Before:        before,
+			After:         after,
+			Colorized:     o.colorized,
+			ReverseFrames: o.reverseFrames,
+		},
+	}
+	if err == nil {
+		return data
+	}
+	data.Message = err.Error()
+	e, ok := err.(Error)
+	if !ok {
+		return data
+	}
+	frames := o.ordered(o.selected(e.StackTrace()))
+	budgets := o.sourceBudgets(len(frames), before, after)
+	data.Frames = make([]TemplateFrame, 0, len(frames))
+	for _, frame := range frames {
+		f := TemplateFrame{Header: frame.String()}
+		if frame.hidden == 0 {
+			f.Func = frame.Func
+			f.Path = displayPath(frame.Path)
+			f.Line = frame.Line
+		}
+		if budget := budgets[frame.index]; withSource && budget.shown && frame.hidden == 0 {
+			window, err := sourceWindow(frame.Frame, budget.before, budget.after)
+			if err != nil {
+				f.SourceError = err.Error()
+			}
+			for _, line := range window {
+				f.Source = append(f.Source, TemplateLine(line))
+			}
+		}
+		data.Frames = append(data.Frames, f)
+	}
+	return data
+}
@@ -0,0 +1,78 @@
+package tracerr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestSprintTemplate(t *testing.T) {
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameC",
+				Line: 17,
+				Path: "error_helper_test.go",
+			},
+			{
+				Func: "main.main",
+				Line: 4,
+				Path: "/src/main.go",
+			},
+		},
+	)
+	tmpl := template.Must(template.New("error").Parse(
+		"{{.Message}} (source: {{.Settings.WithSource}})\n" +
+			"{{range .Frames}}at {{.Func}} ({{.Path}}:{{.Line}})\n" +
+			"{{range .Source}}{{if .Traced}}>{{else}} {{end}} {{.Number}}: {{.Text}}\n{{end}}" +
+			"{{with .SourceError}}  {{.}}\n{{end}}" +
+			"{{end}}",
+	))
+
+	output, tmplErr := tracerr.SprintTemplate(err, tmpl, tracerr.WithSource(1, 1))
+	if tmplErr != nil {
+		t.Fatalf("tracerr.SprintTemplate(err, tmpl) error = %#v", tmplErr)
+	}
+	expected := strings.Join([]string{
+		"some error (source: true)",
+		"at github.com/ztrue/tracerr_test.addFrameC (error_helper_test.go:17)",
+		"  16: func addFrameC(message string) error {",
+		"> 17: \treturn tracerr.New(message)",
+		"  18: }",
+		"at main.main (/src/main.go:4)",
+		"  tracerr: file /src/main.go not found",
+		"",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintTemplate(err, tmpl) = %#v; want %#v", output, expected)
+	}
+
+	output, tmplErr = tracerr.SprintTemplate(err, tmpl, tracerr.WithReversedFrames(true))
+	if tmplErr != nil {
+		t.Fatalf("tracerr.SprintTemplate(err, tmpl) error = %#v", tmplErr)
+	}
+	expected = strings.Join([]string{
+		"some error (source: false)",
+		"at main.main (/src/main.go:4)",
+		"at github.com/ztrue/tracerr_test.addFrameC (error_helper_test.go:17)",
+		"",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintTemplate(err, tmpl) = %#v; want %#v", output, expected)
+	}
+}
+
+func TestSprintTemplateError(t *testing.T) {
+	tmpl := template.Must(template.New("error").Parse("{{.Unknown}}"))
+	output, err := tracerr.SprintTemplate(tracerr.New("some error"), tmpl)
+	if err == nil {
+		t.Errorf("tracerr.SprintTemplate(err, tmpl) error = nil; want error")
+	}
+	if output != "" {
+		t.Errorf("tracerr.SprintTemplate(err, tmpl) = %#v; want empty", output)
+	}
+}
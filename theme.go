@@ -0,0 +1,67 @@
+package tracerr
+
+import (
+	"sync"
+)
+
+// Theme defines colors of colorized output.
+// Each field is an ANSI escape sequence, such as "\x1b[31m",
+// which is followed by text and reset sequence.
+// Empty field means no color.
+type Theme struct {
+	// Message is a color of error message.
+	Message string
+	// Header is a style of frame header.
+	Header string
+	// LineNumber is a color of context line numbers.
+	LineNumber string
+	// TracedLine is a color of traced line.
+	TracedLine string
+	// Warning is a color of notices, such as source file not found.
+	Warning string
+	// Keyword is a color of keywords in syntax highlighted source.
+	Keyword string
+	// String is a color of string literals in syntax highlighted source.
+	String string
+	// Comment is a color of comments in syntax highlighted source.
+	Comment string
+}
+
+// DefaultTheme is a theme used by default.
+var DefaultTheme = Theme{
+	Header:     "\x1b[1m",
+	LineNumber: "\x1b[30m",
+	TracedLine: "\x1b[31m",
+	Warning:    "\x1b[33m",
+	Keyword:    "\x1b[35m",
+	String:     "\x1b[32m",
+	Comment:    "\x1b[36m",
+}
+
+// LightTheme is a theme for terminals with light background.
+var LightTheme = Theme{
+	Header:     "\x1b[1m",
+	LineNumber: "\x1b[90m",
+	TracedLine: "\x1b[31m",
+	Warning:    "\x1b[35m",
+	Keyword:    "\x1b[34m",
+	String:     "\x1b[32m",
+	Comment:    "\x1b[90m",
+}
+
+var themeMutex sync.RWMutex
+
+var theme = DefaultTheme
+
+// SetTheme sets colors of colorized output, DefaultTheme is used by default.
+func SetTheme(t Theme) {
+	themeMutex.Lock()
+	defer themeMutex.Unlock()
+	theme = t
+}
+
+func getTheme() Theme {
+	themeMutex.RLock()
+	defer themeMutex.RUnlock()
+	return theme
+}
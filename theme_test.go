@@ -0,0 +1,65 @@
+package tracerr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestSetTheme(t *testing.T) {
+	defer tracerr.SetTheme(tracerr.DefaultTheme)
+	err := tracerr.CustomError(
+		errors.New("some error"),
+		[]tracerr.Frame{
+			{
+				Func: "github.com/ztrue/tracerr_test.addFrameC",
+				Line: 17,
+				Path: "error_helper_test.go",
+			},
+			{
+				Func: "main.Foo",
+				Line: 42,
+				Path: "/tmp/not_exists.go",
+			},
+		},
+	)
+
+	tracerr.SetTheme(tracerr.Theme{
+		Message:    "<m>",
+		Header:     "<h>",
+		LineNumber: "<n>",
+		TracedLine: "<t>",
+		Warning:    "<w>",
+	})
+	output := tracerr.SprintSourceColor(err, 1, 1)
+	expected := strings.Join([]string{
+		"<m>some error\x1b[0m",
+		"",
+		"<h>error_helper_test.go:17 github.com/ztrue/tracerr_test.addFrameC()\x1b[0m",
+		"<n>16\x1b[0m\tfunc addFrameC(message string) error {",
+		"<t>17\t\treturn tracerr.New(message)\x1b[0m",
+		"<n>18\x1b[0m\t}",
+		"",
+		"<h>/tmp/not_exists.go:42 main.Foo()\x1b[0m",
+		"<w>tracerr: file /tmp/not_exists.go not found\x1b[0m",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	// Empty fields mean no color.
+	tracerr.SetTheme(tracerr.Theme{})
+	output = tracerr.SprintSourceColor(err, 1, 1)
+	if output != tracerr.SprintSource(err, 1, 1) {
+		t.Errorf("output = %#v; want %#v", output, tracerr.SprintSource(err, 1, 1))
+	}
+
+	tracerr.SetTheme(tracerr.LightTheme)
+	output = tracerr.SprintSourceColor(err, 1, 1)
+	row := strings.Split(output, "\n")[3]
+	if row != tracerr.LightTheme.LineNumber+"16\x1b[0m\tfunc addFrameC(message string) error {" {
+		t.Errorf("rows[3] = %#v; want light line number color", row)
+	}
+}
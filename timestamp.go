@@ -0,0 +1,23 @@
+package tracerr
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// skipTimestamp is inverted, so timestamp is captured by default.
+var skipTimestamp atomic.Bool
+
+// SetCaptureTimestamp defines whether time is recorded on error creation,
+// it's enabled by default.
+func SetCaptureTimestamp(enabled bool) {
+	skipTimestamp.Store(!enabled)
+}
+
+// timestamp returns current time or zero time if capturing is disabled.
+func timestamp() time.Time {
+	if skipTimestamp.Load() {
+		return time.Time{}
+	}
+	return time.Now()
+}
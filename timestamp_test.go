@@ -0,0 +1,53 @@
+package tracerr_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestTimestamp(t *testing.T) {
+	defer tracerr.SetCaptureTimestamp(true)
+
+	before := time.Now()
+	err := tracerr.New("some error")
+	after := time.Now()
+	ts := tracerr.Timestamp(err)
+	if ts.Before(before) || ts.After(after) {
+		t.Errorf("tracerr.Timestamp(err) = %v; want between %v and %v", ts, before, after)
+	}
+	if !tracerr.Timestamp(err).Equal(ts) {
+		t.Errorf("tracerr.Timestamp(err) = %v; want unchanged %v", tracerr.Timestamp(err), ts)
+	}
+
+	rows := strings.Split(tracerr.SprintWithOptions(err, tracerr.WithTimestamp(true)), "\n")
+	if rows[1] != ts.Format(time.RFC3339Nano) {
+		t.Errorf("rows[1] = %#v; want %#v", rows[1], ts.Format(time.RFC3339Nano))
+	}
+	rows = strings.Split(tracerr.Sprint(err), "\n")
+	if len(rows) != len(err.StackTrace())+1 {
+		t.Errorf("tracerr.Sprint(err) = %#v; want no timestamp", tracerr.Sprint(err))
+	}
+
+	output, _ := tracerr.SprintJSON(err)
+	var data struct {
+		Time string `json:"time"`
+	}
+	json.Unmarshal([]byte(output), &data)
+	if data.Time != ts.Format(time.RFC3339Nano) {
+		t.Errorf("data.Time = %#v; want %#v", data.Time, ts.Format(time.RFC3339Nano))
+	}
+
+	tracerr.SetCaptureTimestamp(false)
+	err = tracerr.Errorf("some error %d", 1)
+	if !tracerr.Timestamp(err).IsZero() {
+		t.Errorf("tracerr.Timestamp(err) = %v; want zero", tracerr.Timestamp(err))
+	}
+	rows = strings.Split(tracerr.SprintWithOptions(err, tracerr.WithTimestamp(true)), "\n")
+	if len(rows) != len(err.StackTrace())+1 {
+		t.Errorf("tracerr.SprintWithOptions(err) = %#v; want no timestamp", rows)
+	}
+}
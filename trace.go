@@ -0,0 +1,43 @@
+package tracerr
+
+import (
+	"errors"
+)
+
+// Trace is error message with stack trace in a plain structure,
+// such as to copy it to a gRPC status detail message of your own proto
+// and pass it to a client, with no dependency on gRPC.
+//
+// Stack trace exposes internal details of a service,
+// so it should be passed only in non-production environments.
+type Trace struct {
+	// Message contains error message.
+	Message string
+	// Frames contains stack trace, innermost first.
+	Frames []Frame
+}
+
+// EncodeTrace returns error message and stack trace of err as a Trace.
+// Frames are empty if err has no stack trace.
+// Frames are copied, so changes of a Trace don't affect err.
+// It returns nil if err is nil.
+func EncodeTrace(err error) *Trace {
+	if err == nil {
+		return nil
+	}
+	return &Trace{
+		Message: err.Error(),
+		Frames:  append([]Frame(nil), StackTrace(err)...),
+	}
+}
+
+// DecodeTrace creates an error from a Trace, such as received from a server,
+// the same way as CustomError does.
+// Frames are copied, so changes of t don't affect the error.
+// It returns nil if t is nil.
+func DecodeTrace(t *Trace) Error {
+	if t == nil {
+		return nil
+	}
+	return CustomError(errors.New(t.Message), append([]Frame(nil), t.Frames...))
+}
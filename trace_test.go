@@ -0,0 +1,63 @@
+package tracerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestEncodeTrace(t *testing.T) {
+	err := addFrameA("trace error")
+	trace := tracerr.EncodeTrace(err)
+	if trace.Message != "trace error" {
+		t.Errorf("trace.Message = %#v; want %#v", trace.Message, "trace error")
+	}
+	frames := tracerr.StackTrace(err)
+	if len(trace.Frames) != len(frames) {
+		t.Fatalf("len(trace.Frames) = %#v; want %#v", len(trace.Frames), len(frames))
+	}
+
+	decoded := tracerr.DecodeTrace(trace)
+	if decoded.Error() != "trace error" {
+		t.Errorf("decoded.Error() = %#v; want %#v", decoded.Error(), "trace error")
+	}
+	for i, frame := range decoded.StackTrace() {
+		if frame != frames[i] {
+			t.Errorf("decoded.StackTrace()[%#v] = %#v; want %#v", i, frame, frames[i])
+		}
+	}
+	if output, expected := tracerr.Sprint(decoded), tracerr.Sprint(err); output != expected {
+		t.Errorf("tracerr.Sprint(decoded) = %#v; want %#v", output, expected)
+	}
+}
+
+func TestEncodeTraceNoStackTrace(t *testing.T) {
+	if trace := tracerr.EncodeTrace(nil); trace != nil {
+		t.Errorf("tracerr.EncodeTrace(nil) = %#v; want nil", trace)
+	}
+	if err := tracerr.DecodeTrace(nil); err != nil {
+		t.Errorf("tracerr.DecodeTrace(nil) = %#v; want nil", err)
+	}
+	trace := tracerr.EncodeTrace(errors.New("plain error"))
+	if trace.Message != "plain error" || len(trace.Frames) != 0 {
+		t.Errorf("tracerr.EncodeTrace(plain) = %#v; want message with no frames", trace)
+	}
+}
+
+func TestTraceCopiesFrames(t *testing.T) {
+	err := addFrameA("trace error")
+	line := tracerr.StackTrace(err)[0].Line
+	trace := tracerr.EncodeTrace(err)
+	trace.Frames[0].Line = 0
+	if frame := tracerr.StackTrace(err)[0]; frame.Line != line {
+		t.Errorf("tracerr.StackTrace(err)[0].Line = %#v; want %#v", frame.Line, line)
+	}
+
+	trace.Frames[0].Line = line
+	decoded := tracerr.DecodeTrace(trace)
+	trace.Frames[0].Line = 0
+	if frame := decoded.StackTrace()[0]; frame.Line != line {
+		t.Errorf("decoded.StackTrace()[0].Line = %#v; want %#v", frame.Line, line)
+	}
+}
@@ -0,0 +1,124 @@
+package tracerr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestWithTree(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/main.go": {Data: []byte("package main\n\nfunc main() {\n\tpanic(1)\n}\n")},
+	})
+	err := tracerr.Join(
+		tracerr.CustomError(errors.New("first error"), []tracerr.Frame{
+			{Func: "main.foo", Line: 10, Path: "/src/foo.go"},
+			{Func: "main.main", Line: 4, Path: "/src/main.go"},
+		}),
+		tracerr.CustomError(errors.New("second error"), []tracerr.Frame{
+			{Func: "main.main", Line: 4, Path: "/src/main.go"},
+		}),
+		tracerr.CustomError(errors.New("third error"), []tracerr.Frame{
+			{Func: "main.bar", Line: 20, Path: "/src/bar.go"},
+		}),
+	)
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithTree(true))
+	expected := strings.Join([]string{
+		"3 errors occurred:",
+		"│",
+		"├─ first error",
+		"│  /src/foo.go:10 main.foo()",
+		"│  /src/main.go:4 main.main()",
+		"│",
+		"├─ second error",
+		"│  /src/main.go:4 main.main()",
+		"│",
+		"└─ third error",
+		"   /src/bar.go:20 main.bar()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithTree(true), tracerr.WithSource(1, 1))
+	expected = strings.Join([]string{
+		"3 errors occurred:",
+		"│",
+		"├─ first error",
+		"│",
+		"│  /src/foo.go:10 main.foo()",
+		"│  tracerr: file /src/foo.go not found",
+		"│",
+		"│  /src/main.go:4 main.main()",
+		"│  3\tfunc main() {",
+		"│  4\t\tpanic(1)",
+		"│  5\t}",
+		"│",
+		"├─ second error",
+		"│",
+		"│  /src/main.go:4 main.main()",
+		"│  3\tfunc main() {",
+		"│  4\t\tpanic(1)",
+		"│  5\t}",
+		"│",
+		"└─ third error",
+		"",
+		"   /src/bar.go:20 main.bar()",
+		"   tracerr: file /src/bar.go not found",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithTree(true), tracerr.WithCompactSpacing(true))
+	if strings.Contains(output, "│\n") {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want no separator rows", output)
+	}
+
+	single := tracerr.CustomError(errors.New("single error"), []tracerr.Frame{
+		{Func: "main.foo", Line: 10, Path: "/src/foo.go"},
+	})
+	if output := tracerr.SprintWithOptions(single, tracerr.WithTree(true)); output != tracerr.Sprint(single) {
+		t.Errorf("tracerr.SprintWithOptions(single, ...) = %#v; want %#v", output, tracerr.Sprint(single))
+	}
+}
+
+func TestWithTreeNestedJoin(t *testing.T) {
+	err := tracerr.Join(
+		tracerr.Join(
+			tracerr.CustomError(errors.New("first error"), []tracerr.Frame{
+				{Func: "main.foo", Line: 10, Path: "/src/foo.go"},
+			}),
+			tracerr.CustomError(errors.New("second error"), []tracerr.Frame{
+				{Func: "main.main", Line: 4, Path: "/src/main.go"},
+			}),
+		),
+		tracerr.CustomError(errors.New("third error"), []tracerr.Frame{
+			{Func: "main.bar", Line: 20, Path: "/src/bar.go"},
+		}),
+	)
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithTree(true))
+	expected := strings.Join([]string{
+		"2 errors occurred:",
+		"│",
+		"├─ 2 errors occurred:",
+		"│  │",
+		"│  ├─ first error",
+		"│  │  /src/foo.go:10 main.foo()",
+		"│  │",
+		"│  └─ second error",
+		"│     /src/main.go:4 main.main()",
+		"│",
+		"└─ third error",
+		"   /src/bar.go:20 main.bar()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("tracerr.SprintWithOptions(err, ...) = %#v; want %#v", output, expected)
+	}
+}
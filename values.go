@@ -0,0 +1,65 @@
+package tracerr
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// WithValue attaches metadata, such as request ID, to an error
+// and returns a new error with the same message and stack trace.
+// Calls can be chained, values attached earlier are kept,
+// as well as by Wrap and Wrapf.
+//
+// If there is no Error in err chain, stack trace is added the same way as in Wrap.
+// It returns nil if err is nil.
+func WithValue(err error, key, value string) Error {
+	if err == nil {
+		return nil
+	}
+	return derive(err, 0, func(data *errorData) {
+		values := make(map[string]string, len(data.values)+1)
+		for k, v := range data.values {
+			values[k] = v
+		}
+		values[key] = value
+		data.values = values
+	})
+}
+
+// Values returns metadata attached by WithValue.
+// It returns nil if there is no metadata.
+func (e *errorData) Values() map[string]string {
+	if len(e.values) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(e.values))
+	for k, v := range e.values {
+		values[k] = v
+	}
+	return values
+}
+
+// Values returns metadata attached by WithValue to the first Error in err chain.
+// It returns nil if there is no metadata.
+func Values(err error) map[string]string {
+	var e interface{ Values() map[string]string }
+	if !errors.As(err, &e) {
+		return nil
+	}
+	return e.Values()
+}
+
+// formatValues formats values as "key=value" pairs sorted by key.
+func formatValues(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+values[k])
+	}
+	return strings.Join(pairs, " ")
+}
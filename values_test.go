@@ -0,0 +1,89 @@
+package tracerr_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+func TestWithValue(t *testing.T) {
+	cause := errors.New("values error")
+	err := tracerr.WithValue(cause, "request_id", "abc")
+	err = tracerr.WithValue(err, "user_id", "42")
+
+	values := tracerr.Values(err)
+	expected := map[string]string{
+		"request_id": "abc",
+		"user_id":    "42",
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("tracerr.Values(err) = %#v; want %#v", values, expected)
+	}
+	for k, v := range expected {
+		if values[k] != v {
+			t.Errorf("tracerr.Values(err)[%#v] = %#v; want %#v", k, values[k], v)
+		}
+	}
+	if err.Error() != "values error" {
+		t.Errorf("err.Error() = %#v; want %#v", err.Error(), "values error")
+	}
+	if err.Unwrap() != cause {
+		t.Errorf("err.Unwrap() = %#v; want %#v", err.Unwrap(), cause)
+	}
+	if name := err.StackTrace()[0].FuncName(); name != "TestWithValue" {
+		t.Errorf("err.StackTrace()[0].FuncName() = %#v; want %#v", name, "TestWithValue")
+	}
+
+	// Values are kept by wrapping.
+	wrapped := tracerr.Wrapf(err, "while handling")
+	if tracerr.Values(wrapped)["user_id"] != "42" {
+		t.Errorf("tracerr.Values(wrapped) = %#v; want user_id", tracerr.Values(wrapped))
+	}
+	wrapped = tracerr.Wrap(fmt.Errorf("outer: %w", err))
+	if tracerr.Values(wrapped)["request_id"] != "abc" {
+		t.Errorf("tracerr.Values(wrapped) = %#v; want request_id", tracerr.Values(wrapped))
+	}
+
+	// Values of an error are not changed.
+	first := tracerr.WithValue(cause, "a", "1")
+	tracerr.WithValue(first, "b", "2")
+	if len(tracerr.Values(first)) != 1 {
+		t.Errorf("tracerr.Values(first) = %#v; want one value", tracerr.Values(first))
+	}
+
+	if err := tracerr.WithValue(nil, "a", "1"); err != nil {
+		t.Errorf("tracerr.WithValue(nil) = %#v; want nil", err)
+	}
+	if values := tracerr.Values(tracerr.New("no values")); values != nil {
+		t.Errorf("tracerr.Values(tracerr.New()) = %#v; want nil", values)
+	}
+}
+
+func TestSprintWithValues(t *testing.T) {
+	err := tracerr.CustomError(errors.New("values error"), []tracerr.Frame{
+		{Func: "main.foo", Line: 42, Path: "/src/main.go"},
+	})
+	err = tracerr.WithValue(tracerr.WithValue(err, "user_id", "42"), "request_id", "abc")
+
+	output := tracerr.SprintWithOptions(err, tracerr.WithValues(true))
+	expected := strings.Join([]string{
+		"values error",
+		"request_id=abc user_id=42",
+		"/src/main.go:42 main.foo()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+
+	output = tracerr.Sprint(err)
+	expected = strings.Join([]string{
+		"values error",
+		"/src/main.go:42 main.foo()",
+	}, "\n")
+	if output != expected {
+		t.Errorf("output = %#v; want %#v", output, expected)
+	}
+}
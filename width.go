@@ -0,0 +1,103 @@
+package tracerr
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultTerminalWidth is a terminal width in columns used if it's unknown.
+const DefaultTerminalWidth = 80
+
+// queryTerminal returns width of terminal f is attached to,
+// it's a variable to be replaced in tests.
+var queryTerminal = terminalColumns
+
+// TerminalWidth returns width in columns of terminal, which the writer of Print is attached to,
+// os.Stdout by default. If it's not a terminal or its size is unknown,
+// width is taken from COLUMNS environment variable,
+// or it's DefaultTerminalWidth if the variable is not set.
+// Width is detected on each call, so it follows terminal resizes.
+//
+// Terminal size is queried on Linux, macOS and BSD only,
+// on other systems COLUMNS environment variable is used.
+func TerminalWidth() int {
+	f, ok := getWriter(VariantPrint).(*os.File)
+	if !ok {
+		f = os.Stdout
+	}
+	if columns, ok := queryTerminal(f); ok {
+		return columns
+	}
+	if columns, ok := envColumns(); ok {
+		return columns
+	}
+	return DefaultTerminalWidth
+}
+
+// envColumns returns terminal width from COLUMNS environment variable, if it's valid.
+func envColumns() (int, bool) {
+	columns, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS")))
+	if err != nil || columns <= 0 {
+		return 0, false
+	}
+	return columns, true
+}
+
+// sourceTextWidth returns a maximum number of columns of source line text,
+// so source row with line number of width and tab fits into n columns.
+func sourceTextWidth(n, width int, tab string) int {
+	tabColumns := len(tab)
+	if tab == "\t" {
+		tabColumns = 8
+	}
+	return max(n-width-tabColumns, 1)
+}
+
+// wideRanges are East Asian wide and fullwidth characters, which take 2 columns.
+var wideRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115f, Stride: 1},
+		{Lo: 0x2e80, Hi: 0x303e, Stride: 1},
+		{Lo: 0x3041, Hi: 0x33ff, Stride: 1},
+		{Lo: 0x3400, Hi: 0x4dbf, Stride: 1},
+		{Lo: 0x4e00, Hi: 0x9fff, Stride: 1},
+		{Lo: 0xa000, Hi: 0xa4cf, Stride: 1},
+		{Lo: 0xac00, Hi: 0xd7a3, Stride: 1},
+		{Lo: 0xf900, Hi: 0xfaff, Stride: 1},
+		{Lo: 0xfe30, Hi: 0xfe4f, Stride: 1},
+		{Lo: 0xff00, Hi: 0xff60, Stride: 1},
+		{Lo: 0xffe0, Hi: 0xffe6, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1},
+		{Lo: 0x1f900, Hi: 0x1f9ff, Stride: 1},
+		{Lo: 0x20000, Hi: 0x3fffd, Stride: 1},
+	},
+}
+
+// runeColumns returns number of columns r takes in source line text at column col.
+// Tab takes len(tab) columns, or up to the next tab stop if tab is "\t".
+func runeColumns(r rune, col int, tab string) int {
+	switch {
+	case r == '\t' && tab == "\t":
+		return 8 - col%8
+	case r == '\t':
+		return len(tab)
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case unicode.Is(wideRanges, r):
+		return 2
+	}
+	return 1
+}
+
+// textColumns returns number of columns text takes in source row.
+func textColumns(text, tab string) int {
+	col := 0
+	for _, r := range text {
+		col += runeColumns(r, col, tab)
+	}
+	return col
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || dragonfly)
+
+package tracerr
+
+import "os"
+
+// terminalColumns returns false, since terminal size is not queried on this system.
+func terminalColumns(f *os.File) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,124 @@
+package tracerr_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ztrue/tracerr"
+)
+
+type TerminalWidthTestCase struct {
+	Columns  string
+	Expected int
+}
+
+func noTerminal(f *os.File) (int, bool) {
+	return 0, false
+}
+
+func TestTerminalWidth(t *testing.T) {
+	tracerr.SetTerminalQuery(noTerminal)
+	defer tracerr.SetTerminalQuery(nil)
+	cases := []TerminalWidthTestCase{
+		{"", tracerr.DefaultTerminalWidth},
+		{"120", 120},
+		{" 100 ", 100},
+		{"0", tracerr.DefaultTerminalWidth},
+		{"-5", tracerr.DefaultTerminalWidth},
+		{"wide", tracerr.DefaultTerminalWidth},
+	}
+	for _, c := range cases {
+		t.Setenv("COLUMNS", c.Columns)
+		if width := tracerr.TerminalWidth(); width != c.Expected {
+			t.Errorf("COLUMNS=%#v: tracerr.TerminalWidth() = %#v; want %#v", c.Columns, width, c.Expected)
+		}
+	}
+}
+
+func TestTerminalWidthQuery(t *testing.T) {
+	defer tracerr.SetTerminalQuery(nil)
+	defer tracerr.SetDefaultWriter(nil)
+	t.Setenv("COLUMNS", "120")
+	var queried *os.File
+	tracerr.SetTerminalQuery(func(f *os.File) (int, bool) {
+		queried = f
+		return 60, true
+	})
+	if width := tracerr.TerminalWidth(); width != 60 {
+		t.Errorf("tracerr.TerminalWidth() = %#v; want %#v", width, 60)
+	}
+	if queried != os.Stdout {
+		t.Errorf("queried = %#v; want os.Stdout", queried)
+	}
+
+	tracerr.SetDefaultWriter(os.Stderr)
+	tracerr.TerminalWidth()
+	if queried != os.Stderr {
+		t.Errorf("queried = %#v; want os.Stderr", queried)
+	}
+
+	tracerr.SetDefaultWriter(&bytes.Buffer{})
+	tracerr.TerminalWidth()
+	if queried != os.Stdout {
+		t.Errorf("queried = %#v; want os.Stdout", queried)
+	}
+}
+
+func TestTerminalWidthNotTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	defer tracerr.SetDefaultWriter(nil)
+	tracerr.SetDefaultWriter(f)
+	t.Setenv("COLUMNS", "")
+	if width := tracerr.TerminalWidth(); width != tracerr.DefaultTerminalWidth {
+		t.Errorf("tracerr.TerminalWidth() = %#v; want %#v", width, tracerr.DefaultTerminalWidth)
+	}
+}
+
+func TestWithTerminalWidth(t *testing.T) {
+	defer tracerr.SetSourceFS(nil)
+	defer tracerr.SetMaxSourceLineWidth(0)
+	defer tracerr.SetTabWidth(0)
+	defer tracerr.SetTerminalQuery(nil)
+	tracerr.SetTerminalQuery(noTerminal)
+	long := "var s = \"" + strings.Repeat("x", 100) + "\""
+	tracerr.SetSourceFS(fstest.MapFS{
+		"src/main.go": {Data: []byte(long)},
+	})
+	err := sourceFileError("/src/main.go")
+	tracerr.SetTabWidth(2)
+	fit := tracerr.WithTerminalWidth(true)
+
+	t.Setenv("COLUMNS", "40")
+	output := tracerr.SprintWithOptions(err, tracerr.WithSource(0, 0), fit)
+	row := "1  var s = \"" + strings.Repeat("x", 27) + "…"
+	if !strings.HasSuffix(output, "\n"+row) {
+		t.Errorf("output = %#v; want last row %#v", output, row)
+	}
+
+	output = tracerr.SprintWithOptions(err, tracerr.WithSource(0, 0))
+	if !strings.HasSuffix(output, "\n1  "+long) {
+		t.Errorf("output = %#v; want full line", output)
+	}
+
+	tracerr.SetMaxSourceLineWidth(20)
+	output = tracerr.SprintWithOptions(err, tracerr.WithSource(0, 0), fit)
+	row = "1  var s = \"" + strings.Repeat("x", 10) + "…"
+	if !strings.HasSuffix(output, "\n"+row) {
+		t.Errorf("output = %#v; want last row %#v", output, row)
+	}
+
+	tracerr.SetMaxSourceLineWidth(0)
+	t.Setenv("COLUMNS", "")
+	output = tracerr.SprintWithOptions(err, tracerr.WithSource(0, 0), fit)
+	row = "1  var s = \"" + strings.Repeat("x", 67) + "…"
+	if !strings.HasSuffix(output, "\n"+row) {
+		t.Errorf("output = %#v; want last row %#v", output, row)
+	}
+}
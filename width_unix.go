@@ -0,0 +1,36 @@
+//go:build linux || darwin || freebsd || netbsd || dragonfly
+
+package tracerr
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// winsize is a terminal size returned by TIOCGWINSZ ioctl.
+type winsize struct {
+	rows    uint16
+	columns uint16
+	xpixels uint16
+	ypixels uint16
+}
+
+// terminalColumns returns width of terminal f is attached to.
+// It returns false if f is not a terminal.
+func terminalColumns(f *os.File) (int, bool) {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return 0, false
+	}
+	var ws winsize
+	var errno syscall.Errno
+	// Control doesn't switch file to blocking mode, unlike Fd.
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	})
+	if err != nil || errno != 0 || ws.columns == 0 {
+		return 0, false
+	}
+	return int(ws.columns), true
+}
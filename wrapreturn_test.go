@@ -0,0 +1,80 @@
+package tracerr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ztrue/tracerr"
+)
+
+var errWrapReturn = errors.New("wrap return error")
+
+func returnDirectly() (err error) {
+	defer tracerr.WrapReturn(&err)
+	return errWrapReturn
+}
+
+func returnAssigned() (err error) {
+	defer tracerr.WrapReturn(&err)
+	err = errWrapReturn
+	return
+}
+
+func returnWrapped() (err error) {
+	defer tracerr.WrapReturn(&err)
+	for i := 0; i < 2; i++ {
+		defer func() {}()
+	}
+	return fmt.Errorf("wrapped: %w", errWrapReturn)
+}
+
+func returnTraced() (err error) {
+	defer tracerr.WrapReturn(&err)
+	return addFrameA("traced error")
+}
+
+func returnNil() (err error) {
+	defer tracerr.WrapReturn(&err)
+	return nil
+}
+
+type WrapReturnTestCase struct {
+	Func         func() error
+	ExpectedFunc string
+}
+
+func TestWrapReturn(t *testing.T) {
+	cases := []WrapReturnTestCase{
+		{returnDirectly, "github.com/ztrue/tracerr_test.returnDirectly"},
+		{returnAssigned, "github.com/ztrue/tracerr_test.returnAssigned"},
+		{returnWrapped, "github.com/ztrue/tracerr_test.returnWrapped"},
+	}
+	for _, c := range cases {
+		err := c.Func()
+		if !errors.Is(err, errWrapReturn) {
+			t.Errorf("errors.Is(%s(), errWrapReturn) = false; want true", c.ExpectedFunc)
+		}
+		frames := tracerr.StackTrace(err)
+		if len(frames) < 2 {
+			t.Errorf("tracerr.StackTrace(%s()) = %#v; want at least 2 frames", c.ExpectedFunc, frames)
+			continue
+		}
+		if frames[0].Func != c.ExpectedFunc {
+			t.Errorf("tracerr.StackTrace(err)[0].Func = %#v; want %#v", frames[0].Func, c.ExpectedFunc)
+		}
+		if frames[1].Func != "github.com/ztrue/tracerr_test.TestWrapReturn" {
+			t.Errorf("tracerr.StackTrace(err)[1].Func = %#v; want %#v", frames[1].Func, "github.com/ztrue/tracerr_test.TestWrapReturn")
+		}
+	}
+
+	frames := tracerr.StackTrace(returnTraced())
+	if len(frames) == 0 || frames[0].Func != "github.com/ztrue/tracerr_test.addFrameC" || frames[0].Line != 17 {
+		t.Errorf("tracerr.StackTrace(returnTraced()) = %#v; want original stack trace", frames)
+	}
+
+	if err := returnNil(); err != nil {
+		t.Errorf("returnNil() = %#v; want nil", err)
+	}
+	tracerr.WrapReturn(nil)
+}
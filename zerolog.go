@@ -0,0 +1,33 @@
+package tracerr
+
+// FrameFields returns stack trace of err as a list of fields,
+// one map with "func", "file" and "line" keys per frame, innermost first.
+// It returns nil if err has no stack trace.
+//
+// It has no dependency on zerolog, but together with Fields it is enough
+// to implement zerolog.LogObjectMarshaler in a few lines:
+//
+//	import "github.com/rs/zerolog"
+//
+//	type tracedError struct{ error }
+//
+//	func (e tracedError) MarshalZerologObject(ev *zerolog.Event) {
+//		ev.Str("error", e.Error()).Interface("frames", tracerr.FrameFields(e.error))
+//	}
+//
+//	log.Error().EmbedObject(tracedError{err}).Msg("failed")
+func FrameFields(err error) []map[string]interface{} {
+	stackTrace := StackTrace(err)
+	if len(stackTrace) == 0 {
+		return nil
+	}
+	fields := make([]map[string]interface{}, 0, len(stackTrace))
+	for _, frame := range stackTrace {
+		fields = append(fields, map[string]interface{}{
+			"func": frame.Func,
+			"file": frame.Path,
+			"line": frame.Line,
+		})
+	}
+	return fields
+}